@@ -5,7 +5,11 @@ import (
 	"time"
 
 	"github.com/asynkron/protoactor-go/actor"
+	"github.com/example/microshop/pkg/audit"
 	"github.com/example/microshop/pkg/config"
+	"github.com/example/microshop/pkg/discovery"
+	"github.com/example/microshop/pkg/ordercluster"
+	"github.com/example/microshop/pkg/repository"
 	"go.uber.org/zap"
 )
 
@@ -112,55 +116,18 @@ type NotificationResponse struct {
 	Message string
 }
 
-// OrderClusterActor - clustered order actor
-type OrderClusterActor struct {
-	orders map[string]*OrderInfo
-}
-
-func (a *OrderClusterActor) Receive(ctx actor.Context) {
-	switch msg := ctx.Message().(type) {
-	case *actor.Started:
-		a.orders = make(map[string]*OrderInfo)
-
-	case *CreateOrderCluster:
-		orderID := fmt.Sprintf("ORD-%d", time.Now().UnixNano())
-		a.orders[orderID] = &OrderInfo{
-			OrderID:     orderID,
-			UserID:      msg.UserID,
-			Items:       msg.Items,
-			Status:      "pending",
-			CreatedAt:   time.Now(),
-		}
-		ctx.Respond(&OrderResponse{OrderID: orderID, Status: "pending"})
-
-	case *GetOrderStatusCluster:
-		if order, ok := a.orders[msg.OrderID]; ok {
-			ctx.Respond(&OrderStatus{OrderID: order.OrderID, Status: order.Status})
-		} else {
-			ctx.Respond(&OrderStatus{OrderID: msg.OrderID, Status: "not found"})
-		}
-	}
-}
-
-type CreateOrderCluster struct {
-	UserID string
-	Items  []OrderItem
-}
-
-type GetOrderStatusCluster struct {
-	OrderID string
-}
-
-type OrderInfo struct {
-	OrderID   string
-	UserID    string
-	Items     []OrderItem
-	Status    string
-	CreatedAt time.Time
-}
+// GrainActor (the OrderGrain kind), its messages, and the cluster that
+// hosts it live in pkg/ordercluster — it is a virtual actor keyed by
+// OrderID rather than a plain local actor, and pkg/grpc's OrderServer
+// joins the same cluster, so it belongs in an importable package rather
+// than this main package.
 
-// StartActorService starts the ProtoActor service
-func StartActorService(cfg *config.Config, logger *zap.Logger) error {
+// StartActorService starts the ProtoActor service and, if clustering is
+// enabled, joins the order cluster. The returned *ordercluster.Cluster is
+// non-nil only when clustering is enabled; its caller owns the cluster's
+// lifetime and must Shutdown it when the process itself is stopping,
+// since StartActorService returns as soon as the local actors are up.
+func StartActorService(cfg *config.Config, logger *zap.Logger) (*ordercluster.Cluster, error) {
 	// Create actor system
 	system := actor.NewActorSystem()
 
@@ -170,7 +137,7 @@ func StartActorService(cfg *config.Config, logger *zap.Logger) error {
 	})
 	orderPid, err := system.Root.SpawnNamed(orderProps, "order-actor")
 	if err != nil {
-		return fmt.Errorf("failed to spawn order actor: %w", err)
+		return nil, fmt.Errorf("failed to spawn order actor: %w", err)
 	}
 
 	// Start notification actor
@@ -179,12 +146,34 @@ func StartActorService(cfg *config.Config, logger *zap.Logger) error {
 	})
 	_, err = system.Root.SpawnNamed(notificationProps, "notification-actor")
 	if err != nil {
-		return fmt.Errorf("failed to spawn notification actor: %w", err)
+		return nil, fmt.Errorf("failed to spawn notification actor: %w", err)
 	}
 
 	logger.Info("Local actors started",
 		zap.String("order_actor", orderPid.Id))
 
+	var orderCluster *ordercluster.Cluster
+	if cfg.Cluster.Enabled {
+		sd, err := discovery.NewServiceDiscovery(&cfg.Etcd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to etcd for cluster membership: %w", err)
+		}
+
+		mongoRepo, err := repository.NewMongoRepository(&cfg.MongoDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to mongo for order grain persistence: %w", err)
+		}
+
+		auditRecorder := audit.NewRecorder(mongoRepo, logger, cfg.Audit.JournalPath, cfg.Audit.ReplayInterval)
+
+		orderCluster, err = ordercluster.Join(system, cfg, sd.Client(), mongoRepo, auditRecorder, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to join order cluster: %w", err)
+		}
+
+		logger.Info("Joined order cluster", zap.String("cluster", cfg.Cluster.Name))
+	}
+
 	// Example: Send a message to order actor
 	go func() {
 		time.Sleep(2 * time.Second)
@@ -208,5 +197,5 @@ func StartActorService(cfg *config.Config, logger *zap.Logger) error {
 		}
 	}()
 
-	return nil
+	return orderCluster, nil
 }
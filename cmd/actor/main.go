@@ -28,10 +28,16 @@ func main() {
 
 	logger.Info("Starting ProtoActor service")
 
-	// Start actor service
-	if err := actor.StartActorService(cfg, logger); err != nil {
+	// Start actor service. orderCluster is non-nil only when clustering is
+	// enabled; its lifetime belongs to this process, not to
+	// StartActorService, which returns as soon as the local actors are up.
+	orderCluster, err := actor.StartActorService(cfg, logger)
+	if err != nil {
 		logger.Fatal("Failed to start actor service", zap.Error(err))
 	}
+	if orderCluster != nil {
+		defer orderCluster.Shutdown()
+	}
 
 	// Wait for interrupt signal
 	sigCh := make(chan os.Signal, 1)
@@ -10,17 +10,26 @@ import (
 	"github.com/example/microshop/pkg/config"
 	"github.com/example/microshop/pkg/discovery"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func main() {
 	// Load config
-	cfg, err := config.Load("config/config.yaml")
+	configMgr, err := config.NewManager("config/config.yaml")
 	if err != nil {
 		panic(fmt.Sprintf("Failed to load config: %v", err))
 	}
+	cfg := configMgr.Get()
 
-	// Setup logger
-	logger, err := zap.NewProduction()
+	// Setup logger with a mutable level so config reloads can adjust it
+	// without a restart.
+	level := zap.NewAtomicLevel()
+	if err := level.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		level.SetLevel(zapcore.InfoLevel)
+	}
+	zapCfg := zap.NewProductionConfig()
+	zapCfg.Level = level
+	logger, err := zapCfg.Build()
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create logger: %v", err))
 	}
@@ -30,6 +39,20 @@ func main() {
 		zap.Int("port", cfg.Gateway.Port),
 		zap.String("host", cfg.Gateway.Host))
 
+	// Reconfigure subsystems that can't just read the manager lazily (the
+	// gateway's rate limiter and timeout middleware do) whenever the
+	// config file changes.
+	stopWatch := make(chan struct{})
+	go func() {
+		for newCfg := range configMgr.Watch(stopWatch) {
+			if err := level.UnmarshalText([]byte(newCfg.Log.Level)); err != nil {
+				logger.Warn("Ignoring invalid log level from reloaded config", zap.String("level", newCfg.Log.Level))
+			}
+			logger.Info("Config reloaded")
+		}
+	}()
+	defer close(stopWatch)
+
 	// Setup service discovery
 	sd, err := discovery.NewServiceDiscovery(&cfg.Etcd)
 	if err != nil {
@@ -37,7 +60,7 @@ func main() {
 	}
 
 	// Create gateway
-	gw := gateway.NewGateway(cfg, logger, sd)
+	gw := gateway.NewGateway(configMgr, logger, sd)
 	gw.SetupRoutes()
 
 	// Start gateway in goroutine
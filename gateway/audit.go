@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getAuditLogs returns the audit trail for a single entity, newest first.
+// Gated to admins since it surfaces other users' request payloads.
+func (g *Gateway) getAuditLogs(c *gin.Context) {
+	entityID := c.Param("entity_id")
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 64)
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	logs, err := g.mongo.GetAuditLogs(c.Request.Context(), entityID, limit)
+	if err != nil {
+		g.logger.Error("Failed to get audit logs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"logs": logs})
+}
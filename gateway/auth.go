@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/example/microshop/pkg/auth"
+	"github.com/example/microshop/pkg/proto"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type loginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// login exchanges credentials for an access/refresh token pair. User
+// lookup and password verification are delegated to the user service's
+// Authenticate RPC; the gateway only issues the tokens.
+func (g *Gateway) login(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.grpcClients.UserClient().Authenticate(c.Request.Context(), &proto.AuthenticateRequest{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil || resp.User == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	access, refresh, err := g.tokens.IssuePair(resp.User.Id, resp.User.Roles)
+	if err != nil {
+		g.logger.Error("Failed to issue tokens", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": access, "refresh_token": refresh})
+}
+
+// refresh mints a new access/refresh pair from a still-valid refresh
+// token, rotating it so a stolen refresh token can only be used once.
+func (g *Gateway) refresh(c *gin.Context) {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := g.tokens.Parse(req.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if claims.Type != auth.RefreshToken {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token required"})
+		return
+	}
+	if g.blocklist.IsRevoked(c.Request.Context(), claims.ID) || g.blocklist.IsSessionRevoked(c.Request.Context(), claims.SessionID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+		return
+	}
+
+	if err := g.blocklist.Revoke(c.Request.Context(), claims.ID, claims.ExpiresAt.Time); err != nil {
+		g.logger.Warn("Failed to revoke rotated refresh token", zap.Error(err))
+	}
+
+	access, newRefresh, err := g.tokens.IssuePair(claims.UserID, claims.Roles)
+	if err != nil {
+		g.logger.Error("Failed to issue tokens", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": access, "refresh_token": newRefresh})
+}
+
+// logout revokes the presented access token so it can no longer pass
+// AuthMiddleware, even though it has not yet expired, and revokes the
+// whole session so the refresh token issued alongside it can't mint a
+// replacement pair either.
+func (g *Gateway) logout(c *gin.Context) {
+	header := c.GetHeader("Authorization")
+	claims, err := g.tokens.Parse(stripBearer(header))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := g.blocklist.Revoke(c.Request.Context(), claims.ID, claims.ExpiresAt.Time); err != nil {
+		g.logger.Error("Failed to revoke token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+	if err := g.blocklist.RevokeSession(c.Request.Context(), claims.SessionID, time.Now().Add(g.tokens.SessionTTL())); err != nil {
+		g.logger.Error("Failed to revoke session", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func stripBearer(header string) string {
+	const prefix = "Bearer "
+	if len(header) > len(prefix) && header[:len(prefix)] == prefix {
+		return header[len(prefix):]
+	}
+	return header
+}
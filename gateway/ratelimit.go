@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/example/microshop/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitMiddleware enforces the configured per-IP (and, once
+// authenticated, per-user) request budget. It must be registered after
+// RequireAuth on routes that have it, otherwise ContextUserID is never
+// set and every caller falls back to IP-only keying. Route-specific
+// overrides are keyed by "METHOD path" (gin's FullPath, e.g. "POST
+// /api/v1/orders").
+func (g *Gateway) rateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := g.configMgr.Get().RateLimit
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		limit := cfg.DefaultRPS
+		if route, ok := cfg.Routes[c.Request.Method+" "+c.FullPath()]; ok && route.RPS > 0 {
+			limit = route.RPS
+		}
+
+		key := "ip:" + c.ClientIP()
+		if userID, ok := c.Get(auth.ContextUserID); ok {
+			key = "user:" + fmt.Sprint(userID)
+		}
+
+		result, err := g.limiter.Allow(c.Request.Context(), key, limit, cfg.Window)
+		if err != nil {
+			g.logger.Warn("Rate limiter unavailable, failing open")
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+
+		c.Next()
+	}
+}
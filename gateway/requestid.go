@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the header clients may set (or that the gateway
+// generates) to correlate a request across the gateway and the upstream
+// gRPC services.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDMiddleware ensures every request carries a request ID, echoes
+// it on the response, and attaches it as outgoing gRPC metadata so it is
+// forwarded to user/order services without every handler doing it by hand.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+
+		ctx := metadata.AppendToOutgoingContext(c.Request.Context(), "x-request-id", id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
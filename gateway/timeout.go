@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/example/microshop/pkg/config"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// timeoutMiddleware bounds every request's context to a deadline. Clients
+// may request a shorter deadline via X-Request-Timeout; the value is
+// clamped to the live gateway.max_timeout so a route can never be held
+// open longer than the operator allows, even across a config reload.
+func timeoutMiddleware(configMgr *config.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxTimeout := configMgr.Get().Gateway.MaxTimeout
+		timeout := maxTimeout
+		if raw := c.GetHeader("X-Request-Timeout"); raw != "" {
+			if requested, err := time.ParseDuration(raw); err == nil && requested > 0 && requested < maxTimeout {
+				timeout = requested
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// respondUpstreamError maps a gRPC client error to an HTTP status. A
+// circuit-open upstream surfaces as 503 with a Retry-After hint, and a
+// blown deadline surfaces as 504, instead of a generic 500.
+func (g *Gateway) respondUpstreamError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.Unavailable:
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": st.Message()})
+	case codes.DeadlineExceeded:
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "upstream request timed out"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"path"
+
+	"github.com/example/microshop/pkg/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// IDExtractor pulls the audited entity's ID out of a method's request or
+// response message. Registered per RPC by the service that owns the
+// proto types, since this package has no dependency on them.
+type IDExtractor func(req, resp interface{}) string
+
+// redactedFields are stripped from the request payload before it is
+// stored, so secrets never land in the audit trail.
+var redactedFields = []string{"password"}
+
+// UnaryServerInterceptor records one AuditLog entry per call through
+// recorder, keyed by the unqualified RPC name (e.g. "CreateUser"). Entity
+// IDs are resolved via extractors; methods without an entry are still
+// audited, just without an entity_id.
+func UnaryServerInterceptor(service string, recorder *Recorder, extractors map[string]IDExtractor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		method := path.Base(info.FullMethod)
+		entityID := ""
+		if extract, ok := extractors[method]; ok {
+			entityID = extract(req, resp)
+		}
+
+		data := bson.M{"principal": principalFromContext(ctx)}
+		if fields := summarize(req); fields != nil {
+			data["request"] = fields
+		}
+		if err != nil {
+			data["error"] = err.Error()
+		}
+
+		recorder.Record(&repository.AuditLog{
+			Service:  service,
+			Action:   method,
+			EntityID: entityID,
+			Data:     data,
+		})
+
+		return resp, err
+	}
+}
+
+// principalFromContext reads the authenticated user ID the gateway
+// forwards as gRPC metadata, or "" for unauthenticated/internal calls.
+func principalFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("x-user-id")
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// summarize renders a proto request as a redacted map for storage. Returns
+// nil if req isn't a proto.Message (e.g. a nil request on error paths).
+func summarize(req interface{}) bson.M {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+
+	var fields bson.M
+	if err := bson.UnmarshalExtJSON(data, true, &fields); err != nil {
+		return nil
+	}
+	for _, f := range redactedFields {
+		if _, ok := fields[f]; ok {
+			fields[f] = "[redacted]"
+		}
+	}
+	return fields
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/example/microshop/pkg/repository"
+)
+
+// journal is a newline-delimited JSON file holding AuditLog entries that
+// couldn't be written to Mongo yet.
+type journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newJournal(path string) *journal {
+	return &journal{path: path}
+}
+
+func (j *journal) Append(entry *repository.AuditLog) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Pending returns every entry currently journaled, oldest first.
+func (j *journal) Pending() ([]*repository.AuditLog, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return readEntries(j.path)
+}
+
+// Rotate atomically renames the live journal file out of the way so new
+// Append calls start a fresh file, and returns the path of the rotated
+// file for the caller to replay. It returns "" if there was nothing to
+// rotate. This lets replay drain exactly the entries it observed instead
+// of racing with, and deleting, entries appended during replay.
+func (j *journal) Rotate() (string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	rotated := j.path + ".replay"
+	if err := os.Rename(j.path, rotated); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return rotated, nil
+}
+
+// ReadRotated reads back the entries of a file produced by Rotate.
+func (j *journal) ReadRotated(rotatedPath string) ([]*repository.AuditLog, error) {
+	return readEntries(rotatedPath)
+}
+
+// Requeue prepends remaining (entries from a rotated file that replay
+// didn't finish draining) back onto the live journal, ahead of anything
+// appended since Rotate, then removes the rotated file.
+func (j *journal) Requeue(rotatedPath string, remaining []*repository.AuditLog) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, entry := range remaining {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	current, err := os.ReadFile(j.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	buf.Write(current)
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+	return removeIfExists(rotatedPath)
+}
+
+// Discard removes a rotated file once every entry in it has replayed
+// successfully.
+func (j *journal) Discard(rotatedPath string) error {
+	return removeIfExists(rotatedPath)
+}
+
+func readEntries(path string) ([]*repository.AuditLog, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*repository.AuditLog
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry repository.AuditLog
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
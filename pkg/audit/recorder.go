@@ -0,0 +1,171 @@
+// Package audit writes AuditLog entries captured by a gRPC server
+// interceptor into MongoRepository without blocking the RPC that
+// triggered them. Writes are buffered through a worker pool; if Mongo is
+// unreachable, entries fall back to a local-file journal and are
+// replayed once it recovers.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/microshop/pkg/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultQueueSize      = 256
+	defaultWorkerCount    = 4
+	defaultReplayInterval = 30 * time.Second
+)
+
+// Recorder owns the buffered channel + worker pool that writes AuditLog
+// entries to Mongo, plus the journal fallback used when Mongo is down.
+type Recorder struct {
+	mongo   *repository.MongoRepository
+	logger  *zap.Logger
+	journal *journal
+	queue   chan *repository.AuditLog
+	done    chan struct{}
+}
+
+// NewRecorder starts the worker pool and the journal replay loop. journalPath
+// is where entries are parked if a Mongo write fails.
+func NewRecorder(mongo *repository.MongoRepository, logger *zap.Logger, journalPath string, replayInterval time.Duration) *Recorder {
+	if replayInterval <= 0 {
+		replayInterval = defaultReplayInterval
+	}
+
+	r := &Recorder{
+		mongo:   mongo,
+		logger:  logger,
+		journal: newJournal(journalPath),
+		queue:   make(chan *repository.AuditLog, defaultQueueSize),
+		done:    make(chan struct{}),
+	}
+
+	for i := 0; i < defaultWorkerCount; i++ {
+		go r.worker()
+	}
+	go r.replayLoop(replayInterval)
+
+	return r
+}
+
+// Record enqueues an entry without blocking the caller. If the queue is
+// full the entry is journaled immediately rather than dropped.
+func (r *Recorder) Record(entry *repository.AuditLog) {
+	select {
+	case r.queue <- entry:
+	default:
+		if err := r.journal.Append(entry); err != nil {
+			r.logger.Error("audit: failed to journal entry", zap.Error(err))
+		}
+	}
+}
+
+// RecordSync writes entry synchronously instead of handing it to the
+// worker pool, falling back to the journal (for replay once Mongo
+// recovers) the same way write does. Callers that must know whether an
+// entry is at least durably journaled before acknowledging something
+// else — e.g. OrderGrain acking a mutation — should use this instead of
+// the fire-and-forget Record. It only returns an error if both the Mongo
+// write and the journal fallback fail.
+func (r *Recorder) RecordSync(entry *repository.AuditLog) error {
+	return r.write(entry)
+}
+
+// Close stops accepting new entries and lets in-flight writes drain.
+func (r *Recorder) Close() {
+	close(r.done)
+	close(r.queue)
+}
+
+func (r *Recorder) worker() {
+	for entry := range r.queue {
+		r.write(entry)
+	}
+}
+
+// write attempts a synchronous Mongo write, falling back to the journal
+// if it fails. It returns an error only when the journal fallback itself
+// fails, since a journaled entry is still durable — just deferred until
+// the next replay.
+func (r *Recorder) write(entry *repository.AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.mongo.CreateAuditLog(ctx, entry); err != nil {
+		r.logger.Warn("audit: mongo write failed, journaling to disk", zap.Error(err))
+		if jerr := r.journal.Append(entry); jerr != nil {
+			r.logger.Error("audit: failed to journal entry", zap.Error(jerr))
+			return jerr
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) replayLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.replay()
+		}
+	}
+}
+
+// replay flushes journaled entries once Mongo is reachable again. It
+// rotates the journal file first so entries appended mid-replay (by
+// workers still calling Record) land in a fresh file instead of being
+// deleted along with the replayed ones.
+func (r *Recorder) replay() {
+	rotated, err := r.journal.Rotate()
+	if err != nil {
+		r.logger.Warn("audit: failed to rotate journal", zap.Error(err))
+		return
+	}
+	if rotated == "" {
+		return
+	}
+
+	entries, err := r.journal.ReadRotated(rotated)
+	if err != nil {
+		r.logger.Warn("audit: failed to read rotated journal", zap.Error(err))
+		return
+	}
+	if len(entries) == 0 {
+		if err := r.journal.Discard(rotated); err != nil {
+			r.logger.Error("audit: failed to discard empty rotated journal", zap.Error(err))
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := r.mongo.Ping(ctx); err != nil {
+		if rerr := r.journal.Requeue(rotated, entries); rerr != nil {
+			r.logger.Error("audit: failed to requeue journal after failed ping", zap.Error(rerr))
+		}
+		return
+	}
+
+	for i, entry := range entries {
+		if err := r.mongo.CreateAuditLog(ctx, entry); err != nil {
+			r.logger.Warn("audit: replay failed, will retry", zap.Error(err))
+			if rerr := r.journal.Requeue(rotated, entries[i:]); rerr != nil {
+				r.logger.Error("audit: failed to requeue unreplayed entries", zap.Error(rerr))
+			}
+			return
+		}
+	}
+
+	if err := r.journal.Discard(rotated); err != nil {
+		r.logger.Error("audit: failed to discard journal after replay", zap.Error(err))
+	}
+}
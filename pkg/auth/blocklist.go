@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/microshop/pkg/repository"
+)
+
+// Blocklist revokes tokens by jti before their natural expiry, so logout
+// takes effect immediately instead of waiting out the access/refresh TTL.
+type Blocklist struct {
+	redis *repository.RedisRepository
+}
+
+// NewBlocklist wraps the gateway's existing RedisRepository; entries are
+// stored with a TTL matching the token's remaining lifetime so the key
+// space never grows unbounded.
+func NewBlocklist(redis *repository.RedisRepository) *Blocklist {
+	return &Blocklist{redis: redis}
+}
+
+func (b *Blocklist) key(jti string) string {
+	return fmt.Sprintf("auth:blocklist:%s", jti)
+}
+
+func (b *Blocklist) sessionKey(sessionID string) string {
+	return fmt.Sprintf("auth:blocklist:session:%s", sessionID)
+}
+
+// Revoke marks jti as blocked until it would have expired anyway.
+func (b *Blocklist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return b.redis.Set(ctx, b.key(jti), "1", ttl)
+}
+
+// IsRevoked reports whether jti has been revoked.
+func (b *Blocklist) IsRevoked(ctx context.Context, jti string) bool {
+	_, err := b.redis.Get(ctx, b.key(jti))
+	return err == nil
+}
+
+// RevokeSession marks every token sharing sessionID as blocked until
+// expiresAt, so logging out with an access token also blocks the refresh
+// token issued alongside it, even though its jti was never presented.
+func (b *Blocklist) RevokeSession(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return b.redis.Set(ctx, b.sessionKey(sessionID), "1", ttl)
+}
+
+// IsSessionRevoked reports whether sessionID has been revoked.
+func (b *Blocklist) IsSessionRevoked(ctx context.Context, sessionID string) bool {
+	_, err := b.redis.Get(ctx, b.sessionKey(sessionID))
+	return err == nil
+}
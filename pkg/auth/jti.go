@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// randomJTI generates a random token identifier used for the JWT "jti"
+// claim, which the blocklist keys on so logout can revoke a single token
+// without needing to track its full body.
+func randomJTI() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
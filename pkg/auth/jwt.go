@@ -0,0 +1,140 @@
+// Package auth issues and validates the JWT access/refresh tokens used to
+// authenticate gateway requests.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/example/microshop/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidToken is returned when a token fails signature or claim
+	// validation.
+	ErrInvalidToken = errors.New("auth: invalid token")
+	// ErrTokenExpired is returned when a token's exp claim has passed.
+	ErrTokenExpired = errors.New("auth: token expired")
+)
+
+// TokenType distinguishes access tokens from refresh tokens so a refresh
+// token can never be accepted where an access token is expected.
+type TokenType string
+
+const (
+	AccessToken  TokenType = "access"
+	RefreshToken TokenType = "refresh"
+)
+
+// Claims is the set of custom claims embedded in both access and refresh
+// tokens.
+type Claims struct {
+	UserID    string    `json:"user_id"`
+	Roles     []string  `json:"roles"`
+	Type      TokenType `json:"type"`
+	SessionID string    `json:"session_id"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager issues and validates JWTs for the configured algorithm.
+type TokenManager struct {
+	cfg        *config.AuthConfig
+	signingKey interface{}
+	verifyKey  interface{}
+	method     jwt.SigningMethod
+}
+
+// NewTokenManager builds a TokenManager from the gateway's Auth config. It
+// supports HS256 (shared secret) and RS256 (PEM key pair).
+func NewTokenManager(cfg *config.AuthConfig) (*TokenManager, error) {
+	tm := &TokenManager{cfg: cfg}
+
+	switch cfg.Algorithm {
+	case "", "HS256":
+		tm.method = jwt.SigningMethodHS256
+		tm.signingKey = []byte(cfg.Secret)
+		tm.verifyKey = []byte(cfg.Secret)
+	case "RS256":
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RS256 private key: %w", err)
+		}
+		pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RS256 public key: %w", err)
+		}
+		tm.method = jwt.SigningMethodRS256
+		tm.signingKey = priv
+		tm.verifyKey = pub
+	default:
+		return nil, fmt.Errorf("unsupported auth algorithm: %s", cfg.Algorithm)
+	}
+
+	return tm, nil
+}
+
+// IssuePair mints a fresh access/refresh token pair for the given user.
+// Both tokens carry the same SessionID, so revoking a session (e.g. on
+// logout) blocks the refresh token too even though only the access token
+// was presented to do it.
+func (tm *TokenManager) IssuePair(userID string, roles []string) (access string, refresh string, err error) {
+	sessionID := randomJTI()
+	access, err = tm.issue(userID, roles, AccessToken, sessionID, tm.cfg.AccessTTL)
+	if err != nil {
+		return "", "", err
+	}
+	refresh, err = tm.issue(userID, roles, RefreshToken, sessionID, tm.cfg.RefreshTTL)
+	if err != nil {
+		return "", "", err
+	}
+	return access, refresh, nil
+}
+
+func (tm *TokenManager) issue(userID string, roles []string, typ TokenType, sessionID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:    userID,
+		Roles:     roles,
+		Type:      typ,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tm.cfg.Issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ID:        randomJTI(),
+		},
+	}
+	token := jwt.NewWithClaims(tm.method, claims)
+	return token.SignedString(tm.signingKey)
+}
+
+// SessionTTL returns how long a session (and so a RevokeSession entry)
+// needs to live to outlast the longest-lived token in a pair — the
+// refresh token.
+func (tm *TokenManager) SessionTTL() time.Duration {
+	return tm.cfg.RefreshTTL
+}
+
+// Parse validates signature, issuer and expiry, and returns the claims.
+func (tm *TokenManager) Parse(raw string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != tm.method {
+			return nil, ErrInvalidToken
+		}
+		return tm.verifyKey, nil
+	}, jwt.WithIssuer(tm.cfg.Issuer))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
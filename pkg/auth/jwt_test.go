@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/microshop/pkg/config"
+)
+
+func newTestTokenManager(t *testing.T) *TokenManager {
+	t.Helper()
+	tm, err := NewTokenManager(&config.AuthConfig{
+		Algorithm:  "HS256",
+		Secret:     "test-secret",
+		Issuer:     "microshop-test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	return tm
+}
+
+func TestParseDistinguishesTokenType(t *testing.T) {
+	tm := newTestTokenManager(t)
+
+	access, refresh, err := tm.IssuePair("user-1", []string{"customer"})
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		raw      string
+		wantType TokenType
+	}{
+		{"access token", access, AccessToken},
+		{"refresh token", refresh, RefreshToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tm.Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if claims.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", claims.Type, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestIssuePairSharesSessionID(t *testing.T) {
+	tm := newTestTokenManager(t)
+
+	access, refresh, err := tm.IssuePair("user-1", []string{"customer"})
+	if err != nil {
+		t.Fatalf("IssuePair: %v", err)
+	}
+
+	accessClaims, err := tm.Parse(access)
+	if err != nil {
+		t.Fatalf("Parse(access): %v", err)
+	}
+	refreshClaims, err := tm.Parse(refresh)
+	if err != nil {
+		t.Fatalf("Parse(refresh): %v", err)
+	}
+
+	if accessClaims.SessionID == "" {
+		t.Fatal("SessionID not set on access token")
+	}
+	if accessClaims.SessionID != refreshClaims.SessionID {
+		t.Errorf("access SessionID %q != refresh SessionID %q", accessClaims.SessionID, refreshClaims.SessionID)
+	}
+	if accessClaims.ID == refreshClaims.ID {
+		t.Error("access and refresh tokens must not share a jti")
+	}
+}
@@ -0,0 +1,102 @@
+// Package breaker implements a small per-upstream circuit breaker used to
+// short-circuit calls to a failing gRPC backend instead of piling up
+// timeouts behind it.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow when the breaker is open and the call
+// should be short-circuited.
+var ErrOpen = errors.New("breaker: circuit open")
+
+type state int
+
+const (
+	closed state = iota
+	open
+	halfOpen
+)
+
+// Config controls when a Breaker trips and how it recovers.
+type Config struct {
+	// FailureThreshold is the number of consecutive failures that trips
+	// the breaker from closed to open.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before allowing a
+	// single half-open probe through.
+	OpenTimeout time.Duration
+}
+
+// Breaker is a consecutive-failure circuit breaker with a half-open probe.
+// It is safe for concurrent use.
+type Breaker struct {
+	cfg Config
+
+	mu            sync.Mutex
+	st            state
+	failures      int
+	openedAt      time.Time
+	probeInFlight bool
+}
+
+// New creates a Breaker starting in the closed state. Zero-valued fields
+// in cfg fall back to sane defaults (5 failures, 30s open timeout).
+func New(cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	return &Breaker{cfg: cfg, st: closed}
+}
+
+// Allow reports whether a call should proceed. When the breaker is open
+// and the open timeout has not elapsed it returns ErrOpen; once elapsed it
+// admits exactly one half-open probe.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.st {
+	case closed:
+		return nil
+	case open:
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return ErrOpen
+		}
+		if b.probeInFlight {
+			return ErrOpen
+		}
+		b.st = halfOpen
+		b.probeInFlight = true
+		return nil
+	case halfOpen:
+		return ErrOpen
+	}
+	return nil
+}
+
+// Record reports the outcome of a call admitted by Allow.
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.probeInFlight = false
+		b.st = closed
+		return
+	}
+
+	b.probeInFlight = false
+	b.failures++
+	if b.st == halfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.st = open
+		b.openedAt = time.Now()
+	}
+}
@@ -8,13 +8,23 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Etcd     EtcdConfig     `mapstructure:"etcd"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	MySQL    MySQLConfig    `mapstructure:"mysql"`
-	MongoDB  MongoDBConfig  `mapstructure:"mongodb"`
-	Gateway  GatewayConfig  `mapstructure:"gateway"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Etcd           EtcdConfig           `mapstructure:"etcd"`
+	Redis          RedisConfig          `mapstructure:"redis"`
+	MySQL          MySQLConfig          `mapstructure:"mysql"`
+	MongoDB        MongoDBConfig        `mapstructure:"mongodb"`
+	Kafka          KafkaConfig          `mapstructure:"kafka"`
+	Gateway        GatewayConfig        `mapstructure:"gateway"`
+	Auth           AuthConfig           `mapstructure:"auth"`
+	RateLimit      RateLimitConfig      `mapstructure:"rate_limit"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Resiliency     ResiliencyConfig     `mapstructure:"resiliency"`
+	Telemetry      TelemetryConfig      `mapstructure:"telemetry"`
+	Audit          AuditConfig          `mapstructure:"audit"`
+	Log            LogConfig            `mapstructure:"log"`
+	Observability  ObservabilityConfig  `mapstructure:"observability"`
+	Cluster        ClusterConfig        `mapstructure:"cluster"`
+	TLS            TLSConfig            `mapstructure:"tls"`
 }
 
 type ServerConfig struct {
@@ -24,9 +34,9 @@ type ServerConfig struct {
 }
 
 type EtcdConfig struct {
-	Endpoints    []string      `mapstructure:"endpoints"`
-	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
-	Prefix       string        `mapstructure:"prefix"`
+	Endpoints   []string      `mapstructure:"endpoints"`
+	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	Prefix      string        `mapstructure:"prefix"`
 }
 
 type RedisConfig struct {
@@ -52,9 +62,134 @@ type MongoDBConfig struct {
 	Collection string `mapstructure:"collection"`
 }
 
+// KafkaConfig configures the outbox relay's EventBus. When Brokers is
+// empty, services fall back to an in-memory EventBus instead of dialing
+// Kafka, so the outbox still works in dev/test without a cluster.
+type KafkaConfig struct {
+	Brokers []string `mapstructure:"brokers"`
+}
+
 type GatewayConfig struct {
 	Port int    `mapstructure:"port"`
 	Host string `mapstructure:"host"`
+	// MaxTimeout bounds how long any single request may run, regardless
+	// of the X-Request-Timeout header a client sends.
+	MaxTimeout time.Duration `mapstructure:"max_timeout"`
+}
+
+// AuthConfig configures JWT issuance/validation for the gateway's auth
+// subsystem. Secret is used for HS256; PrivateKey/PublicKey (PEM-encoded)
+// are used for RS256. ClientToken, if set, is attached as a bearer token
+// to every outbound gRPC call ClientManager makes, for service-to-service
+// auth with the downstream gRPC services.
+type AuthConfig struct {
+	Algorithm   string        `mapstructure:"algorithm"`
+	Secret      string        `mapstructure:"secret"`
+	PrivateKey  string        `mapstructure:"private_key"`
+	PublicKey   string        `mapstructure:"public_key"`
+	Issuer      string        `mapstructure:"issuer"`
+	AccessTTL   time.Duration `mapstructure:"access_ttl"`
+	RefreshTTL  time.Duration `mapstructure:"refresh_ttl"`
+	ClientToken string        `mapstructure:"client_token"`
+}
+
+// RouteLimit overrides the default rate limit for a single "METHOD path"
+// route key, e.g. "POST /api/v1/orders".
+type RouteLimit struct {
+	RPS int `mapstructure:"rps"`
+}
+
+// RateLimitConfig configures the Redis-backed per-IP and per-user
+// fixed-window rate limiter in front of the gateway.
+type RateLimitConfig struct {
+	Enabled    bool                  `mapstructure:"enabled"`
+	DefaultRPS int                   `mapstructure:"default_rps"`
+	Window     time.Duration         `mapstructure:"window"`
+	Routes     map[string]RouteLimit `mapstructure:"routes"`
+}
+
+// CircuitBreakerConfig configures the per-upstream circuit breaker
+// wrapping outbound gRPC calls.
+type CircuitBreakerConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+}
+
+// ServicePolicy configures the retry/hedge/breaker behavior ClientManager
+// applies to calls against one upstream gRPC service. Zero-valued fields
+// fall back to defaults (1 attempt, no hedging, and the global
+// CircuitBreaker thresholds for the breaker) so a service can opt in to
+// only what it needs.
+type ServicePolicy struct {
+	MaxAttempts      int           `mapstructure:"max_attempts"`
+	PerTryTimeout    time.Duration `mapstructure:"per_try_timeout"`
+	BaseBackoff      time.Duration `mapstructure:"base_backoff"`
+	MaxBackoff       time.Duration `mapstructure:"max_backoff"`
+	HedgeDelay       time.Duration `mapstructure:"hedge_delay"`
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+}
+
+// ResiliencyConfig declares ClientManager's per-service retry/hedge/
+// breaker policy for each upstream gRPC service it dials.
+type ResiliencyConfig struct {
+	UserService  ServicePolicy `mapstructure:"user_service"`
+	OrderService ServicePolicy `mapstructure:"order_service"`
+}
+
+// TelemetryConfig configures OpenTelemetry trace export from the gateway
+// through to the gRPC services.
+type TelemetryConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	ServiceName   string  `mapstructure:"service_name"`
+	OTLPEndpoint  string  `mapstructure:"otlp_endpoint"`
+	SamplingRatio float64 `mapstructure:"sampling_ratio"`
+}
+
+// AuditConfig configures the audit log pipeline each service runs
+// alongside its mongo-backed AuditLog writes.
+type AuditConfig struct {
+	JournalPath    string        `mapstructure:"journal_path"`
+	ReplayInterval time.Duration `mapstructure:"replay_interval"`
+}
+
+// ObservabilityConfig configures the gRPC-side request logging/metrics
+// interceptor OrderServer and UserServer install on their grpc.NewServer.
+type ObservabilityConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	MetricsAddr string `mapstructure:"metrics_addr"`
+}
+
+// ClusterConfig configures the ProtoActor cluster the order service joins
+// to run OrderGrain, a virtual actor keyed by order ID, instead of
+// keeping per-order state in a single process's memory. The cluster
+// reuses the etcd endpoints under Etcd for membership.
+type ClusterConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Name        string        `mapstructure:"name"`
+	SeedNodes   []string      `mapstructure:"seed_nodes"`
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+}
+
+// TLSConfig configures the transport credentials ClientManager dials
+// upstream gRPC services with. With Enabled false, connections stay
+// plaintext (insecure.NewCredentials), matching the original dev-only
+// behavior. With Enabled true and SPIFFESocket unset, CAFile/CertFile/
+// KeyFile drive server-auth TLS (CAFile only) or full mTLS (CertFile and
+// KeyFile also set); these files are watched and hot-reloaded into the
+// live connection's credentials without a redial. With SPIFFESocket set,
+// identity and trust bundle instead come from a SPIFFE/SPIRE Workload API
+// source, which rotates on its own and makes CAFile/CertFile/KeyFile
+// unused.
+type TLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	ServerNameOverride string `mapstructure:"server_name_override"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	SPIFFESocket       string `mapstructure:"spiffe_socket"`
 }
 
 type LogConfig struct {
@@ -82,6 +217,42 @@ func Load(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// Validate sanity-checks fields a reload could plausibly get wrong, so a
+// malformed config file is rejected instead of silently breaking the
+// running process.
+func (c *Config) Validate() error {
+	if c.Gateway.Port != 0 && (c.Gateway.Port < 1 || c.Gateway.Port > 65535) {
+		return fmt.Errorf("gateway.port out of range: %d", c.Gateway.Port)
+	}
+	if c.Gateway.MaxTimeout < 0 {
+		return fmt.Errorf("gateway.max_timeout must not be negative")
+	}
+	if c.RateLimit.Enabled && c.RateLimit.DefaultRPS <= 0 {
+		return fmt.Errorf("rate_limit.default_rps must be positive when rate limiting is enabled")
+	}
+	if c.CircuitBreaker.Enabled && c.CircuitBreaker.FailureThreshold <= 0 {
+		return fmt.Errorf("circuit_breaker.failure_threshold must be positive when enabled")
+	}
+	if c.Auth.Algorithm != "" && c.Auth.Algorithm != "HS256" && c.Auth.Algorithm != "RS256" {
+		return fmt.Errorf("auth.algorithm must be HS256 or RS256, got %q", c.Auth.Algorithm)
+	}
+	if c.Observability.Enabled && c.Observability.MetricsAddr == "" {
+		return fmt.Errorf("observability.metrics_addr must be set when observability is enabled")
+	}
+	if c.Cluster.Enabled && c.Cluster.Name == "" {
+		return fmt.Errorf("cluster.name must be set when cluster is enabled")
+	}
+	if c.Cluster.Enabled && len(c.Cluster.SeedNodes) == 0 {
+		return fmt.Errorf("cluster.seed_nodes must not be empty when cluster is enabled")
+	}
+	if c.TLS.Enabled && c.TLS.SPIFFESocket == "" {
+		if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+			return fmt.Errorf("tls.cert_file and tls.key_file must be set together")
+		}
+	}
+	return nil
+}
+
 func (c *MySQLConfig) DSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		c.Username, c.Password, c.Host, c.Port, c.Database)
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Manager owns a hot-reloadable Config backed by a viper file watcher.
+// Reads are lock-free via an atomic pointer; a failed reload leaves the
+// previously loaded Config in place and is recorded in LastError.
+type Manager struct {
+	v       *viper.Viper
+	current atomic.Pointer[Config]
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// NewManager loads configPath and starts tracking it for changes. Call
+// Watch to begin reacting to them.
+func NewManager(configPath string) (*Manager, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	m := &Manager{v: v}
+	m.current.Store(&cfg)
+	return m, nil
+}
+
+// Get returns the currently active Config. Safe for concurrent use.
+func (m *Manager) Get() *Config {
+	return m.current.Load()
+}
+
+// LastError returns the error from the most recent reload attempt, or nil
+// if the most recent attempt succeeded (or none has happened yet).
+func (m *Manager) LastError() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
+
+// Watch starts viper's file watcher and returns a channel receiving the
+// new Config after a reload that passes validation. The channel buffers
+// one Config; a reload arriving while an earlier one is still unread is
+// not sent, though Get() already returns it. Reloads that fail
+// to parse or validate are rejected, leaving Get() unchanged; the error is
+// available from LastError. The returned channel is closed when stop is
+// closed.
+func (m *Manager) Watch(stop <-chan struct{}) <-chan *Config {
+	out := make(chan *Config, 1)
+
+	// viper never gives us a way to detach OnConfigChange, so a reload
+	// firing after stop would send on (or race the) close of out below.
+	// sendMu serializes sends against the close so a reload either gets
+	// in before out closes or sees closed and backs off, never both.
+	var sendMu sync.Mutex
+	closed := false
+
+	m.v.OnConfigChange(func(_ fsnotify.Event) {
+		var cfg Config
+		if err := m.v.Unmarshal(&cfg); err != nil {
+			m.setLastError(fmt.Errorf("failed to unmarshal reloaded config: %w", err))
+			return
+		}
+		if err := cfg.Validate(); err != nil {
+			m.setLastError(fmt.Errorf("invalid reloaded config: %w", err))
+			return
+		}
+
+		m.setLastError(nil)
+		m.current.Store(&cfg)
+
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case out <- &cfg:
+		default:
+		}
+	})
+	m.v.WatchConfig()
+
+	go func() {
+		<-stop
+		sendMu.Lock()
+		closed = true
+		close(out)
+		sendMu.Unlock()
+	}()
+
+	return out
+}
+
+func (m *Manager) setLastError(err error) {
+	m.mu.Lock()
+	m.lastErr = err
+	m.mu.Unlock()
+}
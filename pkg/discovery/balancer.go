@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+// P2CEWMABalancerName is the gRPC load-balancing policy name P2CEWMA is
+// registered under. Select it via a service config's loadBalancingPolicy,
+// e.g. `{"loadBalancingPolicy":"p2c_ewma"}`.
+const P2CEWMABalancerName = "p2c_ewma"
+
+// RegisterP2CEWMABalancer installs P2CEWMA as a gRPC balancer.Builder
+// under P2CEWMABalancerName. It must run once before dialing with that
+// policy selected, so it's called alongside RegisterResolver. Safe to
+// call more than once.
+func RegisterP2CEWMABalancer() {
+	balancer.Register(base.NewBalancerBuilder(P2CEWMABalancerName, &p2cPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+func instanceKey(inst *ServiceInstance) string {
+	return fmt.Sprintf("%s:%d", inst.Host, inst.Port)
+}
+
+// p2cEWMADecay weights how quickly a new latency sample moves an
+// instance's running average; lower reacts slower but is less noisy.
+const p2cEWMADecay = 0.5
+
+// p2cStats tracks a subchannel's current in-flight count and EWMA
+// latency, the two inputs the P2C picker scores candidates on.
+type p2cStats struct {
+	mu       sync.Mutex
+	ewma     float64 // nanoseconds
+	inFlight int64
+}
+
+func (s *p2cStats) score() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	load := float64(s.inFlight + 1)
+	if s.ewma == 0 {
+		return load
+	}
+	return s.ewma * load
+}
+
+func (s *p2cStats) start() time.Time {
+	s.mu.Lock()
+	s.inFlight++
+	s.mu.Unlock()
+	return time.Now()
+}
+
+func (s *p2cStats) finish(started time.Time) {
+	latency := time.Since(started)
+	sample := float64(latency)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	if s.ewma == 0 {
+		s.ewma = sample
+	} else {
+		s.ewma = p2cEWMADecay*sample + (1-p2cEWMADecay)*s.ewma
+	}
+}
+
+// p2cPickerBuilder adapts power-of-two-choices EWMA-latency balancing to
+// balancer/base's PickerBuilder, which gRPC calls every time the set of
+// READY subchannels changes.
+type p2cPickerBuilder struct{}
+
+func (p2cPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	picker := &p2cPicker{
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		stats: make(map[balancer.SubConn]*p2cStats, len(info.ReadySCs)),
+	}
+	for sc := range info.ReadySCs {
+		picker.subConns = append(picker.subConns, sc)
+		picker.stats[sc] = &p2cStats{}
+	}
+	return picker
+}
+
+// p2cPicker implements power-of-two-choices load balancing: each Pick
+// samples two ready subchannels uniformly at random and routes to
+// whichever has the lower EWMA-latency-weighted load, so one slow
+// replica doesn't keep receiving its equal round-robin share of traffic.
+// A picker is rebuilt wholesale on every READY-set change, so subConns
+// and stats are immutable for the picker's lifetime; only rnd needs
+// locking, since *rand.Rand is not safe for concurrent use.
+type p2cPicker struct {
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	subConns []balancer.SubConn
+	stats    map[balancer.SubConn]*p2cStats
+}
+
+func (p *p2cPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	if len(p.subConns) == 1 {
+		return p.result(p.subConns[0]), nil
+	}
+
+	p.mu.Lock()
+	i := p.rnd.Intn(len(p.subConns))
+	j := p.rnd.Intn(len(p.subConns) - 1)
+	p.mu.Unlock()
+	if j >= i {
+		j++
+	}
+
+	a, c := p.subConns[i], p.subConns[j]
+	if p.stats[a].score() <= p.stats[c].score() {
+		return p.result(a), nil
+	}
+	return p.result(c), nil
+}
+
+func (p *p2cPicker) result(sc balancer.SubConn) balancer.PickResult {
+	st := p.stats[sc]
+	started := st.start()
+	return balancer.PickResult{
+		SubConn: sc,
+		Done: func(balancer.DoneInfo) {
+			st.finish(started)
+		},
+	}
+}
@@ -3,6 +3,8 @@ package discovery
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/example/microshop/pkg/config"
@@ -74,19 +76,107 @@ func (sd *ServiceDiscovery) Discover(ctx context.Context, serviceName string) ([
 		return nil, fmt.Errorf("failed to discover service: %w", err)
 	}
 
-	var instances []*ServiceInstance
+	instances := make([]*ServiceInstance, 0, len(resp.Kvs))
 	for _, kv := range resp.Kvs {
-		addr := string(kv.Value)
-		// Parse addr (simplified)
-		instances = append(instances, &ServiceInstance{
-			Name: serviceName,
-			Host: addr,
-		})
+		inst, err := parseInstance(serviceName, string(kv.Value))
+		if err != nil {
+			continue
+		}
+		instances = append(instances, inst)
 	}
 
 	return instances, nil
 }
 
+// parseInstance splits a "host:port" value (as written by Register) back
+// into its ServiceInstance fields.
+func parseInstance(serviceName, addr string) (*ServiceInstance, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance address %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance port %q: %w", addr, err)
+	}
+	return &ServiceInstance{Name: serviceName, Host: host, Port: port}, nil
+}
+
+// Watch seeds a channel with the current instances for serviceName, then
+// pushes an updated, deduplicated snapshot every time etcd reports a PUT
+// or DELETE under that service's prefix, so callers (a Balancer, a gRPC
+// resolver) can keep a local cache fresh without polling. The channel is
+// closed once ctx is done; callers should drain it until then.
+func (sd *ServiceDiscovery) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInstance, error) {
+	initial, err := sd.Discover(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s%s/", sd.config.Prefix, serviceName)
+	watchCh := sd.client.Watch(ctx, key, clientv3.WithPrefix())
+
+	out := make(chan []*ServiceInstance, 1)
+	out <- initial
+
+	go func() {
+		defer close(out)
+
+		instances := make(map[string]*ServiceInstance, len(initial))
+		for _, inst := range initial {
+			instances[fmt.Sprintf("%s:%d", inst.Host, inst.Port)] = inst
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resp, ok := <-watchCh:
+				if !ok {
+					return
+				}
+				if resp.Err() != nil {
+					continue
+				}
+
+				for _, ev := range resp.Events {
+					inst, err := parseInstance(serviceName, string(ev.Kv.Value))
+					switch ev.Type {
+					case clientv3.EventTypePut:
+						if err == nil {
+							instances[fmt.Sprintf("%s:%d", inst.Host, inst.Port)] = inst
+						}
+					case clientv3.EventTypeDelete:
+						// The delete event's Kv carries no value, so
+						// recover the instance key from the etcd key
+						// instead of the (empty) parsed value.
+						delete(instances, instanceAddrFromKey(string(ev.Kv.Key)))
+					}
+				}
+
+				snapshot := make([]*ServiceInstance, 0, len(instances))
+				for _, inst := range instances {
+					snapshot = append(snapshot, inst)
+				}
+				out <- snapshot
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// instanceAddrFromKey extracts the "host:port" suffix Register appends
+// after the service name, e.g. "/services/user-service/10.0.0.1:50051".
+func instanceAddrFromKey(key string) string {
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] == '/' {
+			return key[i+1:]
+		}
+	}
+	return key
+}
+
 func (sd *ServiceDiscovery) Deregister(ctx context.Context, instance *ServiceInstance) error {
 	key := fmt.Sprintf("%s%s/%s:%d", sd.config.Prefix, instance.Name, instance.Host, instance.Port)
 	_, err := sd.client.Delete(ctx, key)
@@ -99,3 +189,10 @@ func (sd *ServiceDiscovery) Deregister(ctx context.Context, instance *ServiceIns
 func (sd *ServiceDiscovery) Close() error {
 	return sd.client.Close()
 }
+
+// Client exposes the underlying etcd client so other subsystems (the
+// ProtoActor cluster's etcd provider, for instance) can share this
+// connection instead of dialing etcd a second time.
+func (sd *ServiceDiscovery) Client() *clientv3.Client {
+	return sd.client
+}
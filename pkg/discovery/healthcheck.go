@@ -0,0 +1,169 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheckInterval is how often HealthChecker probes each instance's
+// grpc.health.v1.Health/Check endpoint.
+const HealthCheckInterval = 10 * time.Second
+
+// healthCheckTimeout bounds a single probe so one slow or dead instance
+// can't delay checking the rest.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthChecker periodically probes a set of instances via the standard
+// gRPC health-checking protocol and tracks which are currently healthy.
+// This catches an instance that's reachable (etcd still has its lease)
+// but failing internally, something Watch alone can't see.
+type HealthChecker struct {
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	instances []*ServiceInstance
+	healthy   map[string]bool
+	conns     map[string]*grpc.ClientConn
+
+	stop chan struct{}
+}
+
+// NewHealthChecker creates a HealthChecker. Call Start to begin probing.
+func NewHealthChecker(logger *zap.Logger) *HealthChecker {
+	return &HealthChecker{
+		logger:  logger,
+		healthy: make(map[string]bool),
+		conns:   make(map[string]*grpc.ClientConn),
+		stop:    make(chan struct{}),
+	}
+}
+
+// SetInstances updates the set of instances to probe, dropping cached
+// connections and health state for any instance no longer present.
+func (h *HealthChecker) SetInstances(instances []*ServiceInstance) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.instances = instances
+	seen := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		key := instanceKey(inst)
+		seen[key] = true
+		if _, ok := h.healthy[key]; !ok {
+			// Assume healthy until the first probe says otherwise, so a
+			// freshly discovered instance isn't evicted before it has
+			// even been checked once.
+			h.healthy[key] = true
+		}
+	}
+	for key, conn := range h.conns {
+		if !seen[key] {
+			conn.Close()
+			delete(h.conns, key)
+			delete(h.healthy, key)
+		}
+	}
+	for key := range h.healthy {
+		if !seen[key] {
+			delete(h.healthy, key)
+		}
+	}
+}
+
+// Start runs probes every HealthCheckInterval until Stop is called.
+func (h *HealthChecker) Start() {
+	go func() {
+		ticker := time.NewTicker(HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.probeAll()
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends probing and closes every health-check connection.
+func (h *HealthChecker) Stop() {
+	close(h.stop)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conn := range h.conns {
+		conn.Close()
+	}
+}
+
+// Healthy reports whether inst passed its most recent probe. An instance
+// not yet probed is reported healthy.
+func (h *HealthChecker) Healthy(inst *ServiceInstance) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.healthy[instanceKey(inst)]
+}
+
+func (h *HealthChecker) probeAll() {
+	h.mu.Lock()
+	instances := append([]*ServiceInstance(nil), h.instances...)
+	h.mu.Unlock()
+
+	for _, inst := range instances {
+		go h.probe(inst)
+	}
+}
+
+func (h *HealthChecker) probe(inst *ServiceInstance) {
+	key := instanceKey(inst)
+
+	conn, err := h.connFor(key, inst)
+	if err != nil {
+		h.setHealthy(key, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	healthy := err == nil && resp.Status == healthpb.HealthCheckResponse_SERVING
+	if !healthy {
+		h.logger.Warn("health check failed", zap.String("instance", key), zap.Error(err))
+	}
+	h.setHealthy(key, healthy)
+}
+
+func (h *HealthChecker) connFor(key string, inst *ServiceInstance) (*grpc.ClientConn, error) {
+	h.mu.Lock()
+	conn, ok := h.conns[key]
+	h.mu.Unlock()
+	if ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", inst.Host, inst.Port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s for health check: %w", key, err)
+	}
+
+	h.mu.Lock()
+	h.conns[key] = conn
+	h.mu.Unlock()
+	return conn, nil
+}
+
+func (h *HealthChecker) setHealthy(key string, healthy bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.healthy[key] = healthy
+}
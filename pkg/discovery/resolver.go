@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/resolver"
+)
+
+// etcdScheme is the gRPC target scheme RegisterResolver installs, so
+// callers can dial grpc.Dial("etcd:///user-service", ...) and get
+// watch-driven, health-scored routing instead of a static address.
+const etcdScheme = "etcd"
+
+// resolverBuilder adapts a ServiceDiscovery into a resolver.Builder so
+// grpc.Dial can resolve "etcd:///<service>" targets by watching etcd
+// instead of a one-shot lookup.
+type resolverBuilder struct {
+	sd *ServiceDiscovery
+}
+
+// RegisterResolver installs sd as the global resolver for the "etcd"
+// scheme. Call once during process startup, before dialing any
+// "etcd:///<service>" target.
+func RegisterResolver(sd *ServiceDiscovery) {
+	resolver.Register(&resolverBuilder{sd: sd})
+}
+
+func (b *resolverBuilder) Scheme() string { return etcdScheme }
+
+// Build starts watching the service named by target's endpoint and keeps
+// pushing updated address sets to cc for as long as the returned resolver
+// lives. Addresses are filtered to instances currently passing the active
+// health check, so an etcd-registered but internally failing replica
+// drops out of rotation until it recovers.
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	serviceName := target.Endpoint()
+	if serviceName == "" {
+		return nil, fmt.Errorf("etcd resolver: empty service name in target %q", target.URL.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	instances, err := b.sd.Watch(ctx, serviceName)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("etcd resolver: failed to watch %q: %w", serviceName, err)
+	}
+
+	health := NewHealthChecker(zap.NewNop())
+	health.Start()
+
+	r := &etcdResolver{cc: cc, cancel: cancel, health: health}
+	go r.run(instances)
+	return r, nil
+}
+
+// etcdResolver pushes a new resolver.State, filtered by the health
+// checker's current view, every time Watch reports an etcd change and
+// every HealthCheckInterval besides, so an instance that regains health
+// between etcd events is still picked back up.
+type etcdResolver struct {
+	cc     resolver.ClientConn
+	cancel context.CancelFunc
+	health *HealthChecker
+}
+
+func (r *etcdResolver) run(instances <-chan []*ServiceInstance) {
+	defer r.health.Stop()
+
+	var current []*ServiceInstance
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case insts, ok := <-instances:
+			if !ok {
+				return
+			}
+			current = insts
+			r.health.SetInstances(current)
+			r.pushState(current)
+		case <-ticker.C:
+			r.pushState(current)
+		}
+	}
+}
+
+func (r *etcdResolver) pushState(instances []*ServiceInstance) {
+	addrs := make([]resolver.Address, 0, len(instances))
+	for _, inst := range instances {
+		if !r.health.Healthy(inst) {
+			continue
+		}
+		addrs = append(addrs, resolver.Address{Addr: fmt.Sprintf("%s:%d", inst.Host, inst.Port)})
+	}
+	r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+// ResolveNow is a no-op: Watch already pushes a new state on every etcd
+// change, so there's nothing to do on grpc's periodic nudge.
+func (r *etcdResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *etcdResolver) Close() {
+	r.cancel()
+}
@@ -0,0 +1,189 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/example/microshop/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	clientRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_request_duration_seconds",
+		Help:    "Latency of outbound gRPC calls made by ClientManager, by method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	clientRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_requests_total",
+		Help: "Outbound gRPC calls made by ClientManager, by method and status code.",
+	}, []string{"method", "code"})
+
+	clientInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_client_in_flight_requests",
+		Help: "Outbound gRPC calls currently in flight, by method.",
+	}, []string{"method"})
+
+	registerClientMetricsOnce sync.Once
+)
+
+// registerClientMetrics registers the interceptor's collectors with the
+// default Prometheus registry exactly once, so building more than one
+// ClientManager in a process doesn't panic on a duplicate registration.
+func registerClientMetrics() {
+	registerClientMetricsOnce.Do(func() {
+		prometheus.MustRegister(clientRequestDuration, clientRequestsTotal, clientInFlight)
+	})
+}
+
+// loggingUnaryInterceptor logs every outbound call's method, duration, and
+// outcome: errors at Error, everything else at Info.
+func loggingUnaryInterceptor(logger *zap.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		fields := []zap.Field{zap.String("method", method), zap.Duration("duration", time.Since(start))}
+		if err != nil {
+			logger.Error("outbound gRPC call failed", append(fields, zap.Error(err))...)
+		} else {
+			logger.Info("outbound gRPC call", fields...)
+		}
+		return err
+	}
+}
+
+// metricsUnaryInterceptor records latency, a request counter, and an
+// in-flight gauge for every outbound call, labeled by method and the
+// resulting status code.
+func metricsUnaryInterceptor() grpc.UnaryClientInterceptor {
+	registerClientMetrics()
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		clientInFlight.WithLabelValues(method).Inc()
+		defer clientInFlight.WithLabelValues(method).Dec()
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		code := status.Code(err).String()
+
+		clientRequestDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+		clientRequestsTotal.WithLabelValues(method, code).Inc()
+		return err
+	}
+}
+
+// authUnaryInterceptor attaches cfg.ClientToken to outgoing call metadata
+// as a bearer token, so the downstream service's auth middleware sees a
+// caller identity on service-to-service calls. A no-op when no token is
+// configured.
+func authUnaryInterceptor(cfg config.AuthConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if cfg.ClientToken != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+cfg.ClientToken)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// loggingStreamInterceptor logs a stream's method and outcome once it
+// closes, mirroring loggingUnaryInterceptor for streaming calls.
+func loggingStreamInterceptor(logger *zap.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			logger.Error("outbound gRPC stream failed to open",
+				zap.String("method", method), zap.Duration("duration", time.Since(start)), zap.Error(err))
+			return nil, err
+		}
+		return &loggingClientStream{ClientStream: stream, logger: logger, method: method, start: start}, nil
+	}
+}
+
+// loggingClientStream logs once, on the message that ends the stream
+// (io.EOF or any other error), instead of once per message.
+type loggingClientStream struct {
+	grpc.ClientStream
+	logger *zap.Logger
+	method string
+	start  time.Time
+}
+
+func (s *loggingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		return nil
+	}
+
+	fields := []zap.Field{zap.String("method", s.method), zap.Duration("duration", time.Since(s.start))}
+	if err == io.EOF {
+		s.logger.Info("outbound gRPC stream closed", fields...)
+	} else {
+		s.logger.Error("outbound gRPC stream failed", append(fields, zap.Error(err))...)
+	}
+	return err
+}
+
+// metricsStreamInterceptor tracks the same method-labeled in-flight gauge
+// and request counter/duration as metricsUnaryInterceptor, for the
+// lifetime of the stream rather than a single request/response.
+func metricsStreamInterceptor() grpc.StreamClientInterceptor {
+	registerClientMetrics()
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		clientInFlight.WithLabelValues(method).Inc()
+		start := time.Now()
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			clientInFlight.WithLabelValues(method).Dec()
+			code := status.Code(err).String()
+			clientRequestDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+			clientRequestsTotal.WithLabelValues(method, code).Inc()
+			return nil, err
+		}
+
+		return &metricsClientStream{ClientStream: stream, method: method, start: start, done: sync.Once{}}, nil
+	}
+}
+
+// metricsClientStream records the stream's completion exactly once,
+// whichever RecvMsg call first returns an error (including io.EOF).
+type metricsClientStream struct {
+	grpc.ClientStream
+	method string
+	start  time.Time
+	done   sync.Once
+}
+
+func (s *metricsClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.done.Do(func() {
+			clientInFlight.WithLabelValues(s.method).Dec()
+			code := status.Code(err).String()
+			if err == io.EOF {
+				code = "OK"
+			}
+			clientRequestDuration.WithLabelValues(s.method, code).Observe(time.Since(s.start).Seconds())
+			clientRequestsTotal.WithLabelValues(s.method, code).Inc()
+		})
+	}
+	return err
+}
+
+// authStreamInterceptor is authUnaryInterceptor for streaming calls.
+func authStreamInterceptor(cfg config.AuthConfig) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if cfg.ClientToken != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+cfg.ClientToken)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -3,156 +3,332 @@ package grpc
 import (
 	"context"
 	"fmt"
-	"time"
+	"sync"
 
+	"github.com/example/microshop/pkg/breaker"
 	"github.com/example/microshop/pkg/config"
 	"github.com/example/microshop/pkg/discovery"
 	"github.com/example/microshop/pkg/proto"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
-// ClientManager manages gRPC client connections to microservices
+// ClientFactory builds a typed client on top of conn, e.g.
+// proto.NewUserServiceClient. It's stored per-service at Register time and
+// invoked once, when Connect dials that service.
+type ClientFactory func(conn *grpc.ClientConn) interface{}
+
+// serviceRegistration is everything ClientManager needs to dial one
+// upstream service and build its typed client.
+type serviceRegistration struct {
+	name         string
+	staticTarget string
+	policy       config.ServicePolicy
+	breaker      *breaker.Breaker
+	factory      ClientFactory
+}
+
+// RegisterOption customizes a service registered with ClientManager.Register.
+type RegisterOption func(*serviceRegistration)
+
+// WithStaticTarget sets the address dialed when ClientManager has no
+// discovery configured. Services registered without it are only reachable
+// through discovery.
+func WithStaticTarget(addr string) RegisterOption {
+	return func(r *serviceRegistration) { r.staticTarget = addr }
+}
+
+// WithPolicy sets the retry/hedge/breaker policy applied to calls against
+// this service, overriding the zero-valued default (no retry, no hedge,
+// the global CircuitBreaker thresholds).
+func WithPolicy(policy config.ServicePolicy) RegisterOption {
+	return func(r *serviceRegistration) { r.policy = policy }
+}
+
+// ClientManager manages gRPC client connections to microservices. Services
+// are added via Register and dialed by Connect; Get retrieves a
+// registered service's typed client. This keeps adding a new microservice
+// client a config + Register call rather than an edit to this package.
 type ClientManager struct {
 	config    *config.Config
 	discovery *discovery.ServiceDiscovery
 	logger    *zap.Logger
 
-	// gRPC clients
-	userClient  proto.UserServiceClient
-	orderClient proto.OrderServiceClient
+	registrations []*serviceRegistration
+	conns         map[string]*grpc.ClientConn
+	clients       map[string]interface{}
+
+	// healthy/healthEvents are maintained by watchHealth, one goroutine
+	// per connected service holding open a Health/Watch stream;
+	// healthCancel stops them on Close.
+	healthMu     sync.RWMutex
+	healthy      map[string]bool
+	healthEvents chan HealthEvent
+	healthCancel []context.CancelFunc
+
+	// transportCreds is installed on every dial via
+	// grpc.WithTransportCredentials; closeCreds releases whatever
+	// background resource backs it (a file watcher, a SPIFFE workload API
+	// stream). Connect builds both each time it runs, before dialing.
+	transportCreds credentials.TransportCredentials
+	closeCreds     func() error
+
+	// extraUnary/extraStream are appended after the built-in
+	// tracing/logging/metrics/auth defaults, via WithUnaryInterceptor and
+	// WithStreamInterceptor.
+	extraUnary  []grpc.UnaryClientInterceptor
+	extraStream []grpc.StreamClientInterceptor
+}
+
+// ClientManagerOption customizes ClientManager construction.
+type ClientManagerOption func(*ClientManager)
 
-	// gRPC connections
-	userConn  *grpc.ClientConn
-	orderConn *grpc.ClientConn
+// WithUnaryInterceptor appends interceptor to the unary chain every
+// service connection installs, after the built-in defaults.
+func WithUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.extraUnary = append(m.extraUnary, interceptor)
+	}
 }
 
-// NewClientManager creates a new gRPC client manager
-func NewClientManager(cfg *config.Config, logger *zap.Logger, disc *discovery.ServiceDiscovery) *ClientManager {
-	return &ClientManager{
-		config:    cfg,
-		discovery: disc,
-		logger:    logger,
+// WithStreamInterceptor appends interceptor to the stream chain every
+// service connection installs, after the built-in defaults.
+func WithStreamInterceptor(interceptor grpc.StreamClientInterceptor) ClientManagerOption {
+	return func(m *ClientManager) {
+		m.extraStream = append(m.extraStream, interceptor)
 	}
 }
 
-// Connect establishes connections to all microservices
-func (m *ClientManager) Connect() error {
-	// Connect to User Service
-	if err := m.connectUserService(); err != nil {
-		return fmt.Errorf("failed to connect to user service: %w", err)
+// breakerUnaryInterceptor short-circuits calls while b is open, returning
+// codes.Unavailable so HTTP callers can map it straight to a 503.
+func breakerUnaryInterceptor(b *breaker.Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.Allow(); err != nil {
+			return status.Error(codes.Unavailable, "upstream circuit open")
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.Record(err)
+		return err
 	}
+}
 
-	// Connect to Order Service
-	if err := m.connectOrderService(); err != nil {
-		return fmt.Errorf("failed to connect to order service: %w", err)
+// p2cEWMAServiceConfig selects the health-scored P2C-EWMA balancer so
+// calls route to the least-loaded, lowest-latency instance the resolver
+// reports, instead of pinning the connection to the first one.
+var p2cEWMAServiceConfig = fmt.Sprintf(`{"loadBalancingPolicy":%q}`, discovery.P2CEWMABalancerName)
+
+// NewClientManager creates a new gRPC client manager with the user and
+// order services pre-registered, for backward compatibility with
+// UserClient()/OrderClient(). Additional services can be added with
+// Register before calling Connect. opts can add further unary/stream
+// interceptors on top of the built-in tracing, logging, metrics, auth,
+// retry/hedge, and circuit-breaker defaults via WithUnaryInterceptor and
+// WithStreamInterceptor.
+func NewClientManager(cfg *config.Config, logger *zap.Logger, disc *discovery.ServiceDiscovery, opts ...ClientManagerOption) *ClientManager {
+	if disc != nil {
+		discovery.RegisterResolver(disc)
+		discovery.RegisterP2CEWMABalancer()
 	}
 
-	return nil
+	m := &ClientManager{
+		config:       cfg,
+		discovery:    disc,
+		logger:       logger,
+		conns:        make(map[string]*grpc.ClientConn),
+		clients:      make(map[string]interface{}),
+		healthy:      make(map[string]bool),
+		healthEvents: make(chan HealthEvent, 16),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.Register("user-service", func(conn *grpc.ClientConn) interface{} {
+		return proto.NewUserServiceClient(conn)
+	}, WithStaticTarget("localhost:50051"), WithPolicy(cfg.Resiliency.UserService))
+
+	m.Register("order-service", func(conn *grpc.ClientConn) interface{} {
+		return proto.NewOrderServiceClient(conn)
+	}, WithStaticTarget("localhost:50052"), WithPolicy(cfg.Resiliency.OrderService))
+
+	return m
 }
 
-// connectUserService establishes a connection to the user service
-func (m *ClientManager) connectUserService() error {
-	// Default user service address
-	target := "localhost:50051"
+// Register adds a service ClientManager dials on the next Connect call.
+// factory builds the typed client from the dialed connection; it's
+// retrieved afterwards with Get[T](m, name). Registering the same name
+// twice keeps both; Connect dials each registration independently, so
+// don't register a name more than once.
+func (m *ClientManager) Register(name string, factory ClientFactory, opts ...RegisterOption) {
+	reg := &serviceRegistration{name: name, factory: factory}
+	for _, opt := range opts {
+		opt(reg)
+	}
+	reg.breaker = breaker.New(breakerConfigFor(m.config, reg.policy))
+	m.registrations = append(m.registrations, reg)
+}
 
-	// Try to use service discovery if available
-	if m.discovery != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		instances, err := m.discovery.Discover(ctx, "user-service")
-		if err == nil && len(instances) > 0 {
-			target = instances[0].Host
-			m.logger.Info("Discovered user service", zap.String("address", target))
-		} else {
-			m.logger.Info("Using default address for user service", zap.String("address", target))
-		}
+// Get returns the typed client registered under name, built by Connect
+// from that service's factory. It errors if name was never registered or
+// Connect hasn't run yet, or if T doesn't match the type the factory
+// returned.
+func Get[T any](m *ClientManager, name string) (T, error) {
+	var zero T
+
+	c, ok := m.clients[name]
+	if !ok {
+		return zero, fmt.Errorf("grpc: no client connected for service %q", name)
 	}
 
-	m.logger.Info("Connecting to user service", zap.String("target", target))
+	typed, ok := c.(T)
+	if !ok {
+		return zero, fmt.Errorf("grpc: client for service %q is %T, not %T", name, c, zero)
+	}
+	return typed, nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// breakerConfigFor prefers policy's own breaker thresholds, set per
+// service under Resiliency, falling back to the global CircuitBreaker
+// config for a service that doesn't override them.
+func breakerConfigFor(cfg *config.Config, policy config.ServicePolicy) breaker.Config {
+	breakerCfg := breaker.Config{
+		FailureThreshold: cfg.CircuitBreaker.FailureThreshold,
+		OpenTimeout:      cfg.CircuitBreaker.OpenTimeout,
+	}
+	if policy.FailureThreshold > 0 {
+		breakerCfg.FailureThreshold = policy.FailureThreshold
+	}
+	if policy.OpenTimeout > 0 {
+		breakerCfg.OpenTimeout = policy.OpenTimeout
+	}
+	return breakerCfg
+}
 
-	conn, err := grpc.DialContext(ctx, target,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
+// Connect dials every registered service and builds its typed client.
+func (m *ClientManager) Connect() error {
+	creds, closeCreds, err := buildTransportCredentials(m.config.TLS, m.logger)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to build transport credentials: %w", err)
 	}
+	m.transportCreds = creds
+	m.closeCreds = closeCreds
 
-	m.userConn = conn
-	m.userClient = proto.NewUserServiceClient(conn)
+	for _, reg := range m.registrations {
+		conn, err := m.dial(reg.name, reg.staticTarget, reg.breaker, reg.policy)
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", reg.name, err)
+		}
 
-	m.logger.Info("Successfully connected to user service")
-	return nil
-}
+		m.conns[reg.name] = conn
+		m.clients[reg.name] = reg.factory(conn)
 
-// connectOrderService establishes a connection to the order service
-func (m *ClientManager) connectOrderService() error {
-	// Default order service address
-	target := "localhost:50052"
+		healthCtx, cancel := context.WithCancel(context.Background())
+		m.healthCancel = append(m.healthCancel, cancel)
+		go m.watchHealth(healthCtx, reg.name, conn)
 
-	// Try to use service discovery if available
-	if m.discovery != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		instances, err := m.discovery.Discover(ctx, "order-service")
-		if err == nil && len(instances) > 0 {
-			target = instances[0].Host
-			m.logger.Info("Discovered order service", zap.String("address", target))
-		} else {
-			m.logger.Info("Using default address for order service", zap.String("address", target))
-		}
+		m.logger.Info("Successfully connected to service", zap.String("service", reg.name))
 	}
 
-	m.logger.Info("Connecting to order service", zap.String("target", target))
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// dial connects to serviceName, load-balancing across every instance the
+// "etcd" resolver discovers and keeps watching, so the connection
+// survives a single backend going away and spreads load as more come up.
+// Without discovery it falls back to staticTarget, a fixed single-address
+// dial, unchanged from before resolver-based discovery existed. policy
+// configures the retry/hedge behavior installed alongside the breaker.
+//
+// The dial is non-blocking: it returns as soon as the ClientConn is
+// constructed, without waiting for the first connection attempt to
+// succeed. grpc-go connects and reconnects lazily in the background from
+// there, so a service that's briefly unavailable at startup no longer
+// holds up Connect; callers that need a service reachable before
+// proceeding can call WaitForReady.
+func (m *ClientManager) dial(serviceName, staticTarget string, b *breaker.Breaker, policy config.ServicePolicy) (*grpc.ClientConn, error) {
+	unary := append([]grpc.UnaryClientInterceptor{
+		loggingUnaryInterceptor(m.logger),
+		metricsUnaryInterceptor(),
+		authUnaryInterceptor(m.config.Auth),
+		breakerUnaryInterceptor(b),
+		retryUnaryInterceptor(policy),
+		hedgedUnaryInterceptor(policy),
+	}, m.extraUnary...)
+
+	stream := append([]grpc.StreamClientInterceptor{
+		loggingStreamInterceptor(m.logger),
+		metricsStreamInterceptor(),
+		authStreamInterceptor(m.config.Auth),
+	}, m.extraStream...)
+
+	target := staticTarget
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(m.transportCreds),
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
 
-	conn, err := grpc.DialContext(ctx, target,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
-	if err != nil {
-		return err
+	if m.discovery != nil {
+		target = fmt.Sprintf("etcd:///%s", serviceName)
+		opts = append(opts, grpc.WithDefaultServiceConfig(p2cEWMAServiceConfig))
 	}
 
-	m.orderConn = conn
-	m.orderClient = proto.NewOrderServiceClient(conn)
+	m.logger.Info("Connecting to service", zap.String("service", serviceName), zap.String("target", target))
 
-	m.logger.Info("Successfully connected to order service")
-	return nil
+	return grpc.DialContext(context.Background(), target, opts...)
 }
 
-// UserClient returns the user service gRPC client
+// UserClient returns the user service gRPC client, a thin wrapper over
+// Get[proto.UserServiceClient](m, "user-service") kept for callers that
+// predate the generic registry.
 func (m *ClientManager) UserClient() proto.UserServiceClient {
-	return m.userClient
+	client, err := Get[proto.UserServiceClient](m, "user-service")
+	if err != nil {
+		return nil
+	}
+	return client
 }
 
-// OrderClient returns the order service gRPC client
+// OrderClient returns the order service gRPC client, a thin wrapper over
+// Get[proto.OrderServiceClient](m, "order-service") kept for callers that
+// predate the generic registry.
 func (m *ClientManager) OrderClient() proto.OrderServiceClient {
-	return m.orderClient
+	client, err := Get[proto.OrderServiceClient](m, "order-service")
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// Conn returns the raw *grpc.ClientConn dialed for a registered service,
+// for callers like MountGRPCWeb that need the connection itself rather
+// than a typed client built from it.
+func (m *ClientManager) Conn(name string) *grpc.ClientConn {
+	return m.conns[name]
 }
 
 // Close closes all gRPC connections
 func (m *ClientManager) Close() error {
 	var errs []error
 
-	if m.userConn != nil {
-		if err := m.userConn.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("user connection close error: %w", err))
+	for _, cancel := range m.healthCancel {
+		cancel()
+	}
+
+	for name, conn := range m.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s connection close error: %w", name, err))
 		}
 	}
 
-	if m.orderConn != nil {
-		if err := m.orderConn.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("order connection close error: %w", err))
+	if m.closeCreds != nil {
+		if err := m.closeCreds(); err != nil {
+			errs = append(errs, fmt.Errorf("transport credentials close error: %w", err))
 		}
 	}
 
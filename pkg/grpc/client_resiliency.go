@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/example/microshop/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultBaseBackoff/defaultMaxBackoff are retryUnaryInterceptor's backoff
+// bounds when a ServicePolicy leaves them unset.
+const (
+	defaultBaseBackoff = 100 * time.Millisecond
+	defaultMaxBackoff  = 2 * time.Second
+)
+
+// retryableCodes are the codes retryUnaryInterceptor retries: each
+// represents a likely-transient failure rather than one the server
+// permanently rejected the request for.
+var retryableCodes = map[codes.Code]bool{
+	codes.Unavailable:       true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+}
+
+// retryUnaryInterceptor retries a call up to policy.MaxAttempts times
+// (default 1, i.e. no retry) when it fails with a retryableCodes status,
+// waiting a jittered exponential backoff between attempts and bounding
+// each attempt to policy.PerTryTimeout when set.
+func retryUnaryInterceptor(policy config.ServicePolicy) grpc.UnaryClientInterceptor {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 1; attempt <= attempts; attempt++ {
+			callCtx := ctx
+			var cancel context.CancelFunc
+			if policy.PerTryTimeout > 0 {
+				callCtx, cancel = context.WithTimeout(ctx, policy.PerTryTimeout)
+			}
+			err = invoker(callCtx, method, req, reply, cc, opts...)
+			if cancel != nil {
+				cancel()
+			}
+
+			if err == nil || !retryableCodes[status.Code(err)] || attempt == attempts {
+				return err
+			}
+
+			select {
+			case <-time.After(retryBackoff(policy, attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return err
+	}
+}
+
+// retryBackoff returns attempt's exponential-backoff delay (base *
+// 2^(attempt-1), capped at MaxBackoff) with full jitter, so retries from
+// many concurrent callers don't all land on the same instant.
+func retryBackoff(policy config.ServicePolicy, attempt int) time.Duration {
+	base := policy.BaseBackoff
+	if base <= 0 {
+		base = defaultBaseBackoff
+	}
+	max := policy.MaxBackoff
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	d := base << uint(attempt-1)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// hedgedUnaryInterceptor dispatches a second, identical copy of the call
+// after policy.HedgeDelay if the first attempt hasn't returned yet, and
+// takes whichever attempt succeeds first; the other is left to run to
+// completion against the caller's ctx but its result is discarded. A
+// no-op when policy.HedgeDelay is zero.
+func hedgedUnaryInterceptor(policy config.ServicePolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if policy.HedgeDelay <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		type attemptResult struct {
+			reply interface{}
+			err   error
+		}
+
+		results := make(chan attemptResult, 2)
+		launch := func() {
+			r := reflect.New(reflect.TypeOf(reply).Elem()).Interface()
+			err := invoker(ctx, method, req, r, cc, opts...)
+			results <- attemptResult{reply: r, err: err}
+		}
+		go launch()
+
+		timer := time.NewTimer(policy.HedgeDelay)
+		defer timer.Stop()
+
+		pending := 1
+		hedged := false
+		var lastErr error
+		for pending > 0 {
+			select {
+			case a := <-results:
+				pending--
+				if a.err == nil {
+					reflect.ValueOf(reply).Elem().Set(reflect.ValueOf(a.reply).Elem())
+					return nil
+				}
+				lastErr = a.err
+			case <-timer.C:
+				if !hedged {
+					hedged = true
+					pending++
+					go launch()
+				}
+			}
+		}
+		return lastErr
+	}
+}
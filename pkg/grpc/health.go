@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+)
+
+// healthWatchRetryDelay is how long a service's health watcher waits
+// before reopening the Health/Watch stream after it ends, whether from an
+// error or the server closing it.
+const healthWatchRetryDelay = 2 * time.Second
+
+// healthRediscoverTimeout bounds the one-off Discover call rediscover
+// makes to refresh a service's instance list after a health failure.
+const healthRediscoverTimeout = 3 * time.Second
+
+// HealthEvent reports a change in a registered service's health, as seen
+// by ClientManager's grpc.health.v1.Health/Watch stream against it.
+type HealthEvent struct {
+	Service string
+	Healthy bool
+	Err     error
+}
+
+// watchHealth runs for the lifetime of the connection dialed for name,
+// keeping a standard gRPC health-check Watch stream open against it.
+// NOT_SERVING or a stream error mark the service unhealthy, publish a
+// HealthEvent, and call rediscover; after a stream error the stream is
+// reopened after healthWatchRetryDelay. Runs until ctx is cancelled, which
+// Close does via the cancel funcs Connect collects.
+func (m *ClientManager) watchHealth(ctx context.Context, name string, conn *grpc.ClientConn) {
+	client := healthpb.NewHealthClient(conn)
+
+	for ctx.Err() == nil {
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			m.setHealthy(name, false, err)
+			if !m.sleepOrDone(ctx, healthWatchRetryDelay) {
+				return
+			}
+			continue
+		}
+
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				m.setHealthy(name, false, err)
+				m.rediscover(name)
+				break
+			}
+			serving := resp.Status == healthpb.HealthCheckResponse_SERVING
+			m.setHealthy(name, serving, nil)
+			if !serving {
+				m.rediscover(name)
+			}
+		}
+
+		if !m.sleepOrDone(ctx, healthWatchRetryDelay) {
+			return
+		}
+	}
+}
+
+// sleepOrDone waits out d, returning false early (without waiting) if ctx
+// ends first.
+func (m *ClientManager) sleepOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// rediscover asks ServiceDiscovery for a fresh instance list and calls
+// ResolveNow on the service's connection. The fetched list is used only
+// to log a failed lookup, and the etcd resolver's ResolveNow is a no-op,
+// so this does not change the connection's address set; that changes
+// only when the resolver's etcd watch or its HealthChecker tick pushes a
+// new state. A no-op when ClientManager has no discovery configured (the
+// static-target fallback).
+func (m *ClientManager) rediscover(name string) {
+	if m.discovery == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthRediscoverTimeout)
+	defer cancel()
+
+	if _, err := m.discovery.Discover(ctx, name); err != nil {
+		m.logger.Warn("failed to refresh instances after health watch failure",
+			zap.String("service", name), zap.Error(err))
+	}
+
+	if conn, ok := m.conns[name]; ok {
+		conn.ResolveNow(resolver.ResolveNowOptions{})
+	}
+}
+
+func (m *ClientManager) setHealthy(name string, healthy bool, err error) {
+	m.healthMu.Lock()
+	changed := m.healthy[name] != healthy
+	m.healthy[name] = healthy
+	m.healthMu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if healthy {
+		m.logger.Info("service became healthy", zap.String("service", name))
+	} else {
+		m.logger.Warn("service became unhealthy", zap.String("service", name), zap.Error(err))
+	}
+
+	select {
+	case m.healthEvents <- HealthEvent{Service: name, Healthy: healthy, Err: err}:
+	default:
+		// Slow/absent consumer: drop the event rather than block the
+		// watcher, Healthy(name) remains the source of truth.
+	}
+}
+
+// Healthy reports whether name's most recent Health/Watch update was
+// SERVING. A service not yet probed (or never registered) reports
+// healthy, matching discovery.HealthChecker's optimistic default so a
+// connection that hasn't completed its first health update yet isn't
+// treated as down.
+func (m *ClientManager) Healthy(name string) bool {
+	m.healthMu.RLock()
+	defer m.healthMu.RUnlock()
+	healthy, ok := m.healthy[name]
+	return !ok || healthy
+}
+
+// HealthEvents returns the channel ClientManager publishes HealthEvents
+// to as each registered service's Health/Watch stream reports a change.
+// The channel is buffered and lossy: a consumer that falls behind misses
+// intermediate events, but Healthy always reflects the latest state.
+func (m *ClientManager) HealthEvents() <-chan HealthEvent {
+	return m.healthEvents
+}
+
+// WaitForReady blocks until name's connection reaches connectivity.Ready,
+// ctx is done, or the connection shuts down. Connect no longer blocks on
+// dial (connections are established lazily and reconnect on their own),
+// so callers that need a service reachable before proceeding - a startup
+// smoke test, the first request after boot - can wait for it explicitly.
+func (m *ClientManager) WaitForReady(ctx context.Context, name string) error {
+	conn, ok := m.conns[name]
+	if !ok {
+		return fmt.Errorf("grpc: no connection for service %q", name)
+	}
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if state == connectivity.Shutdown {
+			return fmt.Errorf("grpc: connection to %q is shut down", name)
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
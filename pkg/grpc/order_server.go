@@ -7,10 +7,14 @@ import (
 	"net"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
-
+	"github.com/asynkron/protoactor-go/actor"
+	"github.com/example/microshop/pkg/audit"
 	"github.com/example/microshop/pkg/config"
+	"github.com/example/microshop/pkg/discovery"
 	"github.com/example/microshop/pkg/models"
+	"github.com/example/microshop/pkg/observability"
+	"github.com/example/microshop/pkg/ordercluster"
+	"github.com/example/microshop/pkg/outbox"
 	pb "github.com/example/microshop/pkg/proto"
 	"github.com/example/microshop/pkg/repository"
 	"go.uber.org/zap"
@@ -24,14 +28,22 @@ import (
 
 type OrderServer struct {
 	pb.UnimplementedOrderServiceServer
-	db     *gorm.DB
-	redis  *repository.RedisRepository
-	mongo  *repository.MongoRepository
-	logger *zap.Logger
-	config *config.Config
+	db      *gorm.DB
+	redis   *repository.RedisRepository
+	mongo   *repository.MongoRepository
+	audit   *audit.Recorder
+	outbox  *repository.Outbox
+	relay   *outbox.Relay
+	ids     models.IDGenerator
+	metrics *observability.Metrics
+	cluster *ordercluster.Cluster
+	logger  *zap.Logger
+	config  *config.Config
 }
 
 func NewOrderServer(cfg *config.Config, logger *zap.Logger) (*OrderServer, error) {
+	logger = observability.NewSampledLogger(logger)
+
 	// Connect to MySQL
 	db, err := gorm.Open(mysql.Open(cfg.MySQL.DSN()), &gorm.Config{})
 	if err != nil {
@@ -39,7 +51,7 @@ func NewOrderServer(cfg *config.Config, logger *zap.Logger) (*OrderServer, error
 	}
 
 	// Auto migrate
-	if err := db.AutoMigrate(&models.Order{}); err != nil {
+	if err := db.AutoMigrate(&models.Order{}, &repository.OutboxEvent{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate: %w", err)
 	}
 
@@ -52,15 +64,52 @@ func NewOrderServer(cfg *config.Config, logger *zap.Logger) (*OrderServer, error
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
+	auditRecorder := audit.NewRecorder(mongoRepo, logger, cfg.Audit.JournalPath, cfg.Audit.ReplayInterval)
+
+	bus, err := newEventBus(cfg.Kafka)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event bus: %w", err)
+	}
+	relay := outbox.NewRelay(db, bus, logger)
+	relay.Start()
+
+	var orderCluster *ordercluster.Cluster
+	if cfg.Cluster.Enabled {
+		sd, err := discovery.NewServiceDiscovery(&cfg.Etcd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to etcd for cluster membership: %w", err)
+		}
+		orderCluster, err = ordercluster.Join(actor.NewActorSystem(), cfg, sd.Client(), mongoRepo, auditRecorder, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to join order cluster: %w", err)
+		}
+	}
+
 	return &OrderServer{
-		db:     db,
-		redis:  redisRepo,
-		mongo:  mongoRepo,
-		logger: logger,
-		config: cfg,
+		db:      db,
+		redis:   redisRepo,
+		mongo:   mongoRepo,
+		audit:   auditRecorder,
+		outbox:  repository.NewOutbox(),
+		relay:   relay,
+		ids:     models.UUIDGenerator{},
+		metrics: observability.NewMetrics(logger),
+		cluster: orderCluster,
+		logger:  logger,
+		config:  cfg,
 	}, nil
 }
 
+// newEventBus dials Kafka if brokers are configured, falling back to an
+// in-memory bus so the outbox still works without a cluster (local dev,
+// tests).
+func newEventBus(cfg config.KafkaConfig) (outbox.EventBus, error) {
+	if len(cfg.Brokers) == 0 {
+		return outbox.NewInMemoryEventBus(), nil
+	}
+	return outbox.NewKafkaEventBus(cfg.Brokers)
+}
+
 func (s *OrderServer) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
 	lis, err := net.Listen("tcp", addr)
@@ -68,10 +117,17 @@ func (s *OrderServer) Start() error {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
 
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		observability.UnaryServerInterceptor("order-service", s.logger, s.metrics),
+		audit.UnaryServerInterceptor("order-service", s.audit, orderAuditExtractors()),
+	))
 	pb.RegisterOrderServiceServer(srv, s)
 	reflection.Register(srv)
 
+	if s.config.Observability.Enabled {
+		s.metrics.Serve(s.config.Observability.MetricsAddr)
+	}
+
 	s.logger.Info("Order service started", zap.String("address", addr))
 
 	return srv.Serve(lis)
@@ -85,10 +141,11 @@ func (s *OrderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderReques
 	}
 
 	order := &models.Order{
-		ID:          generateUUID(),
+		ID:          s.ids.NewID(),
 		UserID:      req.UserId,
 		TotalAmount: totalAmount,
 		Status:      "pending",
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -109,8 +166,24 @@ func (s *OrderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderReques
 	}
 	order.Items = string(itemsJSON)
 
-	if err := s.db.WithContext(ctx).Create(order).Error; err != nil {
-		s.logger.Error("Failed to create order", zap.Error(err))
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		return s.outbox.Append(tx, repository.Event{
+			AggregateType: "order",
+			AggregateID:   order.ID,
+			EventType:     "OrderCreated",
+			Payload: map[string]interface{}{
+				"order_id":     order.ID,
+				"user_id":      order.UserID,
+				"status":       order.Status,
+				"total_amount": totalAmount,
+			},
+		})
+	})
+	if err != nil {
+		observability.LoggerFrom(ctx).Error("Failed to create order", zap.Error(err))
 		return &pb.CreateOrderResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to create order")
 	}
 
@@ -121,14 +194,6 @@ func (s *OrderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderReques
 		Status: order.Status,
 	})
 
-	// Audit log
-	go s.mongo.CreateAuditLog(context.Background(), &repository.AuditLog{
-		Service:  "order-service",
-		Action:   "create_order",
-		EntityID: order.ID,
-		Data:     bson.M{"user_id": order.UserID, "total_amount": totalAmount},
-	})
-
 	return &pb.CreateOrderResponse{
 		Order: &pb.Order{
 			Id:          order.ID,
@@ -136,6 +201,7 @@ func (s *OrderServer) CreateOrder(ctx context.Context, req *pb.CreateOrderReques
 			Items:       req.Items,
 			TotalAmount: totalAmount,
 			Status:      order.Status,
+			Version:     order.Version,
 			CreatedAt:   order.CreatedAt.Unix(),
 			UpdatedAt:   order.UpdatedAt.Unix(),
 		},
@@ -175,6 +241,7 @@ func (s *OrderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*p
 			Items:       items,
 			TotalAmount: order.TotalAmount,
 			Status:      order.Status,
+			Version:     order.Version,
 			CreatedAt:   order.CreatedAt.Unix(),
 			UpdatedAt:   order.UpdatedAt.Unix(),
 		},
@@ -201,7 +268,7 @@ func (s *OrderServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest)
 		// Parse items from JSON
 		var itemsData []models.OrderItem
 		if err := json.Unmarshal([]byte(o.Items), &itemsData); err != nil {
-			s.logger.Warn("Failed to parse items for order", zap.String("order_id", o.ID), zap.Error(err))
+			observability.LoggerFrom(ctx).Warn("Failed to parse items for order", zap.String("order_id", o.ID), zap.Error(err))
 			itemsData = []models.OrderItem{}
 		}
 
@@ -220,6 +287,7 @@ func (s *OrderServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest)
 			Items:       items,
 			TotalAmount: o.TotalAmount,
 			Status:      o.Status,
+			Version:     o.Version,
 			CreatedAt:   o.CreatedAt.Unix(),
 			UpdatedAt:   o.UpdatedAt.Unix(),
 		}
@@ -240,25 +308,77 @@ func (s *OrderServer) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrder
 		return &pb.UpdateOrderStatusResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to update order")
 	}
 
+	if s.cluster != nil {
+		resp, err := s.cluster.RequestOrderGrain(ctx, order.ID, &ordercluster.UpdateOrderStatusCluster{
+			OrderID: order.ID,
+			Status:  req.Status,
+		})
+		if err != nil {
+			return &pb.UpdateOrderStatusResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to update order via cluster")
+		}
+		grainStatus, ok := resp.(*ordercluster.OrderStatus)
+		if !ok || grainStatus.Status == "not found" {
+			return &pb.UpdateOrderStatusResponse{Error: "order not found in cluster"}, status.Error(codes.NotFound, "order not found in cluster")
+		}
+		if grainStatus.Err != "" {
+			return &pb.UpdateOrderStatusResponse{Error: grainStatus.Err}, status.Error(codes.Internal, "failed to durably record order status change")
+		}
+		order.Status = grainStatus.Status
+		s.redis.Del(ctx, fmt.Sprintf("order:%s", req.OrderId))
+		return s.updateOrderStatusResponse(order)
+	}
+
 	updates := map[string]interface{}{
 		"status":     req.Status,
+		"version":    order.Version + 1,
 		"updated_at": time.Now(),
 	}
 
-	if err := s.db.WithContext(ctx).Model(&order).Updates(updates).Error; err != nil {
+	var rowsAffected int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND version = ?", order.ID, order.Version).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		return s.outbox.Append(tx, repository.Event{
+			AggregateType: "order",
+			AggregateID:   order.ID,
+			EventType:     "OrderStatusUpdated",
+			Payload: map[string]interface{}{
+				"order_id": order.ID,
+				"status":   req.Status,
+			},
+		})
+	})
+	if err != nil {
 		return &pb.UpdateOrderStatusResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to update order")
 	}
+	if rowsAffected == 0 {
+		return &pb.UpdateOrderStatusResponse{Error: "concurrent modification"}, status.Error(codes.Aborted, "concurrent modification")
+	}
+	order.Version++
 
 	// Invalidate cache
 	s.redis.Del(ctx, fmt.Sprintf("order:%s", req.OrderId))
 
-	// Parse items from JSON
+	return s.updateOrderStatusResponse(order)
+}
+
+// updateOrderStatusResponse builds the UpdateOrderStatusResponse for order,
+// shared by the MySQL compare-and-swap path and the cluster-delegated path
+// so both return items parsed the same way.
+func (s *OrderServer) updateOrderStatusResponse(order models.Order) (*pb.UpdateOrderStatusResponse, error) {
 	var itemsData []models.OrderItem
 	if err := json.Unmarshal([]byte(order.Items), &itemsData); err != nil {
 		return &pb.UpdateOrderStatusResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to parse items")
 	}
 
-	// Convert items to proto format
 	items := make([]*pb.OrderItem, len(itemsData))
 	for i, item := range itemsData {
 		items[i] = &pb.OrderItem{
@@ -275,7 +395,8 @@ func (s *OrderServer) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrder
 			UserId:      order.UserID,
 			Items:       items,
 			TotalAmount: order.TotalAmount,
-			Status:      req.Status,
+			Status:      order.Status,
+			Version:     order.Version,
 			CreatedAt:   order.CreatedAt.Unix(),
 			UpdatedAt:   time.Now().Unix(),
 		},
@@ -284,6 +405,16 @@ func (s *OrderServer) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrder
 
 func (s *OrderServer) Close() error {
 	s.redis.Close()
+	s.audit.Close()
+	if err := s.relay.Close(); err != nil {
+		s.logger.Warn("Failed to close outbox relay", zap.Error(err))
+	}
+	if err := s.metrics.Close(); err != nil {
+		s.logger.Warn("Failed to close metrics listener", zap.Error(err))
+	}
+	if s.cluster != nil {
+		s.cluster.Shutdown()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	return s.mongo.Close(ctx)
@@ -292,3 +423,28 @@ func (s *OrderServer) Close() error {
 func (s *OrderServer) Redis() *repository.RedisRepository {
 	return s.redis
 }
+
+// orderAuditExtractors maps each audited RPC to the accessor that pulls its
+// entity ID out of the request/response pair.
+func orderAuditExtractors() map[string]audit.IDExtractor {
+	return map[string]audit.IDExtractor{
+		"CreateOrder": func(_, resp interface{}) string {
+			if r, ok := resp.(*pb.CreateOrderResponse); ok && r.Order != nil {
+				return r.Order.Id
+			}
+			return ""
+		},
+		"GetOrder": func(req, _ interface{}) string {
+			if r, ok := req.(*pb.GetOrderRequest); ok {
+				return r.Id
+			}
+			return ""
+		},
+		"UpdateOrderStatus": func(req, _ interface{}) string {
+			if r, ok := req.(*pb.UpdateOrderStatusRequest); ok {
+				return r.OrderId
+			}
+			return ""
+		},
+	}
+}
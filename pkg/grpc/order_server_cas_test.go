@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/microshop/pkg/config"
+	"github.com/example/microshop/pkg/models"
+	pb "github.com/example/microshop/pkg/proto"
+	"github.com/example/microshop/pkg/repository"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestOrderServer builds an OrderServer against an in-memory sqlite DB
+// instead of MySQL, with no cluster/outbox relay wired up, just enough to
+// drive UpdateOrderStatus's compare-and-swap path directly.
+func newTestOrderServer(t *testing.T) (*OrderServer, models.Order) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Order{}, &repository.OutboxEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	items, err := json.Marshal([]models.OrderItem{{ProductID: "p1", ProductName: "Widget", Quantity: 1, Price: 9.99}})
+	if err != nil {
+		t.Fatalf("marshal items: %v", err)
+	}
+	order := models.Order{
+		ID:          "order-cas-1",
+		UserID:      "user-1",
+		Items:       string(items),
+		TotalAmount: 9.99,
+		Status:      "pending",
+		Version:     1,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatalf("seed order: %v", err)
+	}
+
+	s := &OrderServer{
+		db:     db,
+		redis:  repository.NewRedisRepository(&config.RedisConfig{Addr: "127.0.0.1:1"}),
+		outbox: repository.NewOutbox(),
+		logger: zap.NewNop(),
+	}
+	return s, order
+}
+
+// TestUpdateOrderStatusCASRace drives two concurrent UpdateOrderStatus
+// calls against the same order version. Exactly one must win the
+// compare-and-swap and bump the version; the other must be told about
+// the concurrent modification instead of silently overwriting the
+// winner's update.
+func TestUpdateOrderStatusCASRace(t *testing.T) {
+	s, order := newTestOrderServer(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([]*pb.UpdateOrderStatusResponse, 2)
+	statuses := []string{"shipped", "cancelled"}
+
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			resp, _ := s.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{
+				OrderId: order.ID,
+				Status:  statuses[i],
+			})
+			results[i] = resp
+		}()
+	}
+	wg.Wait()
+
+	wins, conflicts := 0, 0
+	for _, resp := range results {
+		switch {
+		case resp.Order != nil:
+			wins++
+		case resp.Error == "concurrent modification":
+			conflicts++
+		default:
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+	}
+
+	if wins != 1 || conflicts != 1 {
+		t.Fatalf("want exactly one winner and one conflict, got %d wins and %d conflicts", wins, conflicts)
+	}
+
+	var stored models.Order
+	if err := s.db.First(&stored, "id = ?", order.ID).Error; err != nil {
+		t.Fatalf("reload order: %v", err)
+	}
+	if stored.Version != order.Version+1 {
+		t.Errorf("Version = %d, want %d", stored.Version, order.Version+1)
+	}
+}
@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"github.com/example/microshop/pkg/proto"
+	"github.com/example/microshop/pkg/transcode"
+	"github.com/gin-gonic/gin"
+	pb "google.golang.org/protobuf/proto"
+)
+
+// MountGRPCWeb exposes the user and order services over gRPC-Web so
+// browser clients can call them without going through the hand-written
+// REST handlers in gateway.SetupRoutes.
+func (m *ClientManager) MountGRPCWeb(router gin.IRoutes) {
+	transcode.MountGRPCWeb(router, m.Conn("user-service"), userRules())
+	transcode.MountGRPCWeb(router, m.Conn("order-service"), orderRules())
+}
+
+func userRules() []transcode.Rule {
+	return []transcode.Rule{
+		{FullMethod: "/user.UserService/CreateUser", NewRequest: func() pb.Message { return &proto.CreateUserRequest{} }, NewReply: func() pb.Message { return &proto.CreateUserResponse{} }},
+		{FullMethod: "/user.UserService/GetUser", NewRequest: func() pb.Message { return &proto.GetUserRequest{} }, NewReply: func() pb.Message { return &proto.GetUserResponse{} }},
+		{FullMethod: "/user.UserService/ListUsers", NewRequest: func() pb.Message { return &proto.ListUsersRequest{} }, NewReply: func() pb.Message { return &proto.ListUsersResponse{} }},
+		{FullMethod: "/user.UserService/UpdateUser", NewRequest: func() pb.Message { return &proto.UpdateUserRequest{} }, NewReply: func() pb.Message { return &proto.UpdateUserResponse{} }},
+		{FullMethod: "/user.UserService/DeleteUser", NewRequest: func() pb.Message { return &proto.DeleteUserRequest{} }, NewReply: func() pb.Message { return &proto.DeleteUserResponse{} }},
+	}
+}
+
+func orderRules() []transcode.Rule {
+	return []transcode.Rule{
+		{FullMethod: "/order.OrderService/CreateOrder", NewRequest: func() pb.Message { return &proto.CreateOrderRequest{} }, NewReply: func() pb.Message { return &proto.CreateOrderResponse{} }},
+		{FullMethod: "/order.OrderService/GetOrder", NewRequest: func() pb.Message { return &proto.GetOrderRequest{} }, NewReply: func() pb.Message { return &proto.GetOrderResponse{} }},
+		{FullMethod: "/order.OrderService/ListOrders", NewRequest: func() pb.Message { return &proto.ListOrdersRequest{} }, NewReply: func() pb.Message { return &proto.ListOrdersResponse{} }},
+		{FullMethod: "/order.OrderService/UpdateOrderStatus", NewRequest: func() pb.Message { return &proto.UpdateOrderStatusRequest{} }, NewReply: func() pb.Message { return &proto.UpdateOrderStatusResponse{} }},
+	}
+}
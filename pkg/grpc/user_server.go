@@ -4,13 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
+	"github.com/example/microshop/pkg/audit"
 	"github.com/example/microshop/pkg/config"
 	"github.com/example/microshop/pkg/models"
-	pb "github.com/example/microshop/proto/user"
+	"github.com/example/microshop/pkg/observability"
+	"github.com/example/microshop/pkg/outbox"
 	"github.com/example/microshop/pkg/repository"
+	pb "github.com/example/microshop/proto/user"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
@@ -24,11 +30,18 @@ type UserServer struct {
 	db      *gorm.DB
 	redis   *repository.RedisRepository
 	mongo   *repository.MongoRepository
+	audit   *audit.Recorder
+	outbox  *repository.Outbox
+	relay   *outbox.Relay
+	ids     models.IDGenerator
+	metrics *observability.Metrics
 	logger  *zap.Logger
 	config  *config.Config
 }
 
 func NewUserServer(cfg *config.Config, logger *zap.Logger) (*UserServer, error) {
+	logger = observability.NewSampledLogger(logger)
+
 	// Connect to MySQL
 	db, err := gorm.Open(mysql.Open(cfg.MySQL.DSN()), &gorm.Config{})
 	if err != nil {
@@ -36,7 +49,7 @@ func NewUserServer(cfg *config.Config, logger *zap.Logger) (*UserServer, error)
 	}
 
 	// Auto migrate
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &repository.OutboxEvent{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate: %w", err)
 	}
 
@@ -49,12 +62,26 @@ func NewUserServer(cfg *config.Config, logger *zap.Logger) (*UserServer, error)
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
+	auditRecorder := audit.NewRecorder(mongoRepo, logger, cfg.Audit.JournalPath, cfg.Audit.ReplayInterval)
+
+	bus, err := newEventBus(cfg.Kafka)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event bus: %w", err)
+	}
+	relay := outbox.NewRelay(db, bus, logger)
+	relay.Start()
+
 	return &UserServer{
-		db:     db,
-		redis:  redisRepo,
-		mongo:  mongoRepo,
-		logger: logger,
-		config: cfg,
+		db:      db,
+		redis:   redisRepo,
+		mongo:   mongoRepo,
+		audit:   auditRecorder,
+		outbox:  repository.NewOutbox(),
+		relay:   relay,
+		ids:     models.UUIDGenerator{},
+		metrics: observability.NewMetrics(logger),
+		logger:  logger,
+		config:  cfg,
 	}, nil
 }
 
@@ -65,27 +92,56 @@ func (s *UserServer) Start() error {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
 
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		observability.UnaryServerInterceptor("user-service", s.logger, s.metrics),
+		audit.UnaryServerInterceptor("user-service", s.audit, userAuditExtractors()),
+	))
 	pb.RegisterUserServiceServer(srv, s)
 	reflection.Register(srv)
 
+	if s.config.Observability.Enabled {
+		s.metrics.Serve(s.config.Observability.MetricsAddr)
+	}
+
 	s.logger.Info("User service started", zap.String("address", addr))
 
 	return srv.Serve(lis)
 }
 
 func (s *UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return &pb.CreateUserResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to hash password")
+	}
+
 	user := &models.User{
-		ID:        generateUUID(),
-		Name:      req.Name,
-		Email:     req.Email,
-		Phone:     req.Phone,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:           s.ids.NewID(),
+		Name:         req.Name,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		PasswordHash: string(hash),
+		Roles:        "user",
+		Version:      1,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
-	if err := s.db.WithContext(ctx).Create(user).Error; err != nil {
-		s.logger.Error("Failed to create user", zap.Error(err))
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return s.outbox.Append(tx, repository.Event{
+			AggregateType: "user",
+			AggregateID:   user.ID,
+			EventType:     "UserCreated",
+			Payload: map[string]interface{}{
+				"user_id": user.ID,
+				"email":   user.Email,
+			},
+		})
+	})
+	if err != nil {
+		observability.LoggerFrom(ctx).Error("Failed to create user", zap.Error(err))
 		return &pb.CreateUserResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to create user")
 	}
 
@@ -97,20 +153,13 @@ func (s *UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 		Phone: user.Phone,
 	})
 
-	// Audit log
-	go s.mongo.CreateAuditLog(context.Background(), &repository.AuditLog{
-		Service:  "user-service",
-		Action:   "create_user",
-		EntityID: user.ID,
-		Data:     bson.M{"name": user.Name, "email": user.Email},
-	})
-
 	return &pb.CreateUserResponse{
 		User: &pb.User{
 			Id:        user.ID,
 			Name:      user.Name,
 			Email:     user.Email,
 			Phone:     user.Phone,
+			Version:   user.Version,
 			CreatedAt: user.CreatedAt.Unix(),
 			UpdatedAt: user.UpdatedAt.Unix(),
 		},
@@ -130,6 +179,7 @@ func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 			},
 		}, nil
 	}
+	observability.LoggerFrom(ctx).Debug("cache miss", zap.String("user_id", req.Id))
 
 	var user models.User
 	if err := s.db.WithContext(ctx).Where("id = ?", req.Id).First(&user).Error; err != nil {
@@ -153,6 +203,33 @@ func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 			Name:      user.Name,
 			Email:     user.Email,
 			Phone:     user.Phone,
+			Version:   user.Version,
+			CreatedAt: user.CreatedAt.Unix(),
+			UpdatedAt: user.UpdatedAt.Unix(),
+		},
+	}, nil
+}
+
+// Authenticate verifies an email/password pair and returns the matching
+// user along with its roles, for the gateway's login flow.
+func (s *UserServer) Authenticate(ctx context.Context, req *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("email = ?", req.Email).First(&user).Error; err != nil {
+		return &pb.AuthenticateResponse{Error: "invalid credentials"}, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return &pb.AuthenticateResponse{Error: "invalid credentials"}, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	return &pb.AuthenticateResponse{
+		User: &pb.User{
+			Id:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			Phone:     user.Phone,
+			Roles:     strings.Split(user.Roles, ","),
+			Version:   user.Version,
 			CreatedAt: user.CreatedAt.Unix(),
 			UpdatedAt: user.UpdatedAt.Unix(),
 		},
@@ -178,6 +255,7 @@ func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*
 			Name:      u.Name,
 			Email:     u.Email,
 			Phone:     u.Phone,
+			Version:   u.Version,
 			CreatedAt: u.CreatedAt.Unix(),
 			UpdatedAt: u.UpdatedAt.Unix(),
 		}
@@ -200,6 +278,7 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 
 	updates := map[string]interface{}{
 		"updated_at": time.Now(),
+		"version":    user.Version + 1,
 	}
 	if req.Name != "" {
 		updates["name"] = req.Name
@@ -211,9 +290,35 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		updates["phone"] = req.Phone
 	}
 
-	if err := s.db.WithContext(ctx).Model(&user).Updates(updates).Error; err != nil {
+	var rowsAffected int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND version = ?", user.ID, user.Version).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		return s.outbox.Append(tx, repository.Event{
+			AggregateType: "user",
+			AggregateID:   user.ID,
+			EventType:     "UserUpdated",
+			Payload: map[string]interface{}{
+				"user_id": user.ID,
+				"email":   user.Email,
+			},
+		})
+	})
+	if err != nil {
 		return &pb.UpdateUserResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to update user")
 	}
+	if rowsAffected == 0 {
+		return &pb.UpdateUserResponse{Error: "concurrent modification"}, status.Error(codes.Aborted, "concurrent modification")
+	}
+	user.Version++
 
 	// Invalidate cache
 	s.redis.Del(ctx, fmt.Sprintf("user:%s", req.Id))
@@ -224,6 +329,7 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 			Name:      user.Name,
 			Email:     user.Email,
 			Phone:     user.Phone,
+			Version:   user.Version,
 			CreatedAt: user.CreatedAt.Unix(),
 			UpdatedAt: user.UpdatedAt.Unix(),
 		},
@@ -231,7 +337,20 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 }
 
 func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
-	if err := s.db.WithContext(ctx).Delete(&models.User{}, "id = ?", req.Id).Error; err != nil {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.User{}, "id = ?", req.Id).Error; err != nil {
+			return err
+		}
+		return s.outbox.Append(tx, repository.Event{
+			AggregateType: "user",
+			AggregateID:   req.Id,
+			EventType:     "UserDeleted",
+			Payload: map[string]interface{}{
+				"user_id": req.Id,
+			},
+		})
+	})
+	if err != nil {
 		return &pb.DeleteUserResponse{Error: err.Error()}, status.Error(codes.Internal, "failed to delete user")
 	}
 
@@ -245,17 +364,51 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest)
 
 func (s *UserServer) Close() error {
 	s.redis.Close()
+	s.audit.Close()
+	if err := s.relay.Close(); err != nil {
+		s.logger.Warn("Failed to close outbox relay", zap.Error(err))
+	}
+	if err := s.metrics.Close(); err != nil {
+		s.logger.Warn("Failed to close metrics listener", zap.Error(err))
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	return s.mongo.Close(ctx)
 }
 
-import (
-	"net"
-
-	"go.mongodb.org/mongo-driver/bson"
-)
-
-func generateUUID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+// userAuditExtractors maps each audited RPC to the accessor that pulls its
+// entity ID out of the request/response pair.
+func userAuditExtractors() map[string]audit.IDExtractor {
+	return map[string]audit.IDExtractor{
+		"CreateUser": func(_, resp interface{}) string {
+			if r, ok := resp.(*pb.CreateUserResponse); ok && r.User != nil {
+				return r.User.Id
+			}
+			return ""
+		},
+		"GetUser": func(req, _ interface{}) string {
+			if r, ok := req.(*pb.GetUserRequest); ok {
+				return r.Id
+			}
+			return ""
+		},
+		"UpdateUser": func(req, _ interface{}) string {
+			if r, ok := req.(*pb.UpdateUserRequest); ok {
+				return r.Id
+			}
+			return ""
+		},
+		"DeleteUser": func(req, _ interface{}) string {
+			if r, ok := req.(*pb.DeleteUserRequest); ok {
+				return r.Id
+			}
+			return ""
+		},
+		"Authenticate": func(_, resp interface{}) string {
+			if r, ok := resp.(*pb.AuthenticateResponse); ok && r.User != nil {
+				return r.User.Id
+			}
+			return ""
+		},
+	}
 }
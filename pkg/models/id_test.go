@@ -0,0 +1,44 @@
+package models
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestUUIDGeneratorNoCollisionsUnderConcurrency exercises NewID from many
+// goroutines at once, which is how OrderServer/UserServer call it under
+// concurrent requests; a collision here would mean two rows racing for
+// the same primary key.
+func TestUUIDGeneratorNoCollisionsUnderConcurrency(t *testing.T) {
+	const (
+		goroutines = 50
+		perRoutine = 200
+	)
+
+	gen := UUIDGenerator{}
+	ids := make(chan string, goroutines*perRoutine)
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perRoutine; j++ {
+				ids <- gen.NewID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]struct{}, goroutines*perRoutine)
+	for id := range ids {
+		if id == "" {
+			t.Fatal("NewID returned an empty ID")
+		}
+		if _, ok := seen[id]; ok {
+			t.Fatalf("NewID produced a duplicate: %s", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
@@ -5,14 +5,18 @@ import (
 )
 
 type Order struct {
-	ID         string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
-	UserID     string    `gorm:"type:varchar(36);not null;index" json:"user_id"`
-	Items      string    `gorm:"type:text" json:"items"` // JSON string
-	TotalAmount float64  `gorm:"type:decimal(10,2)" json:"total_amount"`
-	Status     string    `gorm:"type:varchar(20);default:'pending'" json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	DeletedAt  *time.Time `gorm:"index" json:"-"`
+	ID          string  `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID      string  `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	Items       string  `gorm:"type:text" json:"items"` // JSON string
+	TotalAmount float64 `gorm:"type:decimal(10,2)" json:"total_amount"`
+	Status      string  `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	// Version is incremented on every update and used as a compare-and-swap
+	// token so two concurrent updates to the same row can't silently clobber
+	// each other.
+	Version   int64      `gorm:"not null;default:1" json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"-"`
 }
 
 func (Order) TableName() string {
@@ -7,12 +7,18 @@ import (
 )
 
 type User struct {
-	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
-	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Phone     string    `gorm:"type:varchar(20)" json:"phone"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Name         string `gorm:"type:varchar(100);not null" json:"name"`
+	Email        string `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
+	Phone        string `gorm:"type:varchar(20)" json:"phone"`
+	PasswordHash string `gorm:"type:varchar(100);not null" json:"-"`
+	Roles        string `gorm:"type:varchar(100);default:'user'" json:"-"`
+	// Version is incremented on every update and used as a compare-and-swap
+	// token so two concurrent updates to the same row can't silently clobber
+	// each other.
+	Version   int64          `gorm:"not null;default:1" json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
@@ -0,0 +1,76 @@
+package observability
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor extracts or generates a request ID, derives a
+// *zap.Logger carrying request_id/method/peer that handlers retrieve via
+// LoggerFrom, records per-method latency and message counters on metrics,
+// and recovers from handler panics rather than crashing the process.
+func UnaryServerInterceptor(service string, base *zap.Logger, metrics *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		method := path.Base(info.FullMethod)
+		requestID := requestIDFromContext(ctx)
+
+		logger := base.With(
+			zap.String("request_id", requestID),
+			zap.String("method", method),
+			zap.String("peer", peerAddr(ctx)),
+		)
+		ctx = withLogger(ctx, logger)
+
+		metrics.msgReceived.WithLabelValues(service, method).Inc()
+
+		start := time.Now()
+		code := codes.OK
+
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("panic in gRPC handler", zap.Any("panic", r))
+				err = status.Errorf(codes.Internal, "internal error")
+				code = codes.Internal
+			}
+			metrics.handlingSeconds.WithLabelValues(service, method, code.String()).Observe(time.Since(start).Seconds())
+		}()
+
+		resp, err = handler(ctx, req)
+		if err != nil {
+			code = status.Code(err)
+			return resp, err
+		}
+
+		metrics.msgSent.WithLabelValues(service, method).Inc()
+		return resp, err
+	}
+}
+
+// requestIDFromContext reads x-request-id off the incoming metadata
+// (forwarded by the gateway), generating a new one when the RPC arrives
+// without it.
+func requestIDFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return uuid.NewString()
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return p.Addr.String()
+}
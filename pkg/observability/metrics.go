@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Metrics holds the Prometheus collectors the unary interceptor updates
+// for every RPC a service handles, plus the HTTP listener that exposes
+// them on /metrics. It is separate from the gateway's telemetry.Metrics,
+// which instruments inbound HTTP rather than gRPC.
+type Metrics struct {
+	handlingSeconds *prometheus.HistogramVec
+	msgReceived     *prometheus.CounterVec
+	msgSent         *prometheus.CounterVec
+
+	logger *zap.Logger
+	server *http.Server
+}
+
+// NewMetrics registers the gRPC server collectors with the default
+// Prometheus registry, which Serve exposes, and returns a Metrics ready
+// to pass to UnaryServerInterceptor.
+func NewMetrics(logger *zap.Logger) *Metrics {
+	m := &Metrics{
+		handlingSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Latency of gRPC unary calls in seconds, by service, method, and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"service", "method", "code"}),
+		msgReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Total gRPC requests received, by service and method.",
+		}, []string{"service", "method"}),
+		msgSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Total gRPC responses sent successfully, by service and method.",
+		}, []string{"service", "method"}),
+		logger: logger,
+	}
+
+	prometheus.MustRegister(m.handlingSeconds, m.msgReceived, m.msgSent)
+	return m
+}
+
+// Serve starts the /metrics HTTP listener in the background. Bind
+// failures are logged rather than returned since Serve is called from the
+// servers' Start, which goes on to start the gRPC server regardless.
+func (m *Metrics) Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	m.server = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			m.logger.Error("metrics listener failed", zap.String("addr", addr), zap.Error(err))
+		}
+	}()
+}
+
+// Close shuts down the metrics listener, if one was started.
+func (m *Metrics) Close() error {
+	if m.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.server.Shutdown(ctx)
+}
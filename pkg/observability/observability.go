@@ -0,0 +1,39 @@
+// Package observability installs a chained grpc.UnaryServerInterceptor on
+// OrderServer and UserServer that derives a request-scoped logger and
+// records Prometheus metrics for every RPC, so a failed call can be
+// correlated back to its request ID and latency without every handler
+// doing that bookkeeping by hand.
+package observability
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey int
+
+const loggerContextKey contextKey = iota
+
+// RequestIDMetadataKey is the incoming gRPC metadata key the interceptor
+// reads the request ID from. The gateway sets it on every outbound call
+// (see gateway/requestid.go); a missing or empty value means the RPC came
+// from somewhere else (a test, another internal caller), so the
+// interceptor mints a fresh ID instead of leaving calls uncorrelated.
+const RequestIDMetadataKey = "x-request-id"
+
+// LoggerFrom returns the request-scoped logger UnaryServerInterceptor
+// stashed on ctx. Handlers should use this instead of the server's bare
+// *zap.Logger so every log line carries request_id/method/peer. Falls
+// back to a no-op logger if called outside of an intercepted RPC (e.g.
+// from a test) rather than panicking.
+func LoggerFrom(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*zap.Logger); ok {
+		return l
+	}
+	return zap.NewNop()
+}
+
+func withLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
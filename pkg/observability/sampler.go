@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewSampledLogger wraps base so repetitive DEBUG lines (a "cache miss" on
+// every read, say) are rate-limited instead of either spamming the logs
+// or being dropped outright. INFO-and-above entries always pass through
+// in full, since sampling those could hide a real incident.
+func NewSampledLogger(base *zap.Logger) *zap.Logger {
+	return base.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &debugSamplingCore{
+			full:    core,
+			sampled: zapcore.NewSamplerWithOptions(core, time.Second, 5, 100),
+		}
+	}))
+}
+
+// debugSamplingCore routes DEBUG entries through a sampled core and
+// everything else through the unsampled one.
+type debugSamplingCore struct {
+	full    zapcore.Core
+	sampled zapcore.Core
+}
+
+func (c *debugSamplingCore) Enabled(lvl zapcore.Level) bool {
+	return c.full.Enabled(lvl)
+}
+
+func (c *debugSamplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugSamplingCore{full: c.full.With(fields), sampled: c.sampled.With(fields)}
+}
+
+func (c *debugSamplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level == zapcore.DebugLevel {
+		return c.sampled.Check(ent, ce)
+	}
+	return c.full.Check(ent, ce)
+}
+
+func (c *debugSamplingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.full.Write(ent, fields)
+}
+
+func (c *debugSamplingCore) Sync() error {
+	return c.full.Sync()
+}
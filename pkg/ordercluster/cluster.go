@@ -0,0 +1,85 @@
+package ordercluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asynkron/protoactor-go/actor"
+	"github.com/asynkron/protoactor-go/cluster"
+	"github.com/asynkron/protoactor-go/cluster/clusterproviders/etcd"
+	"github.com/asynkron/protoactor-go/cluster/identitylookup/disthash"
+	"github.com/asynkron/protoactor-go/remote"
+	"github.com/example/microshop/pkg/audit"
+	"github.com/example/microshop/pkg/config"
+	"github.com/example/microshop/pkg/repository"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// defaultCallTimeout bounds a RequestOrderGrain call when ctx carries no
+// deadline of its own.
+const defaultCallTimeout = 5 * time.Second
+
+// Cluster wraps the ProtoActor cluster member hosting OrderGrain, so
+// callers route messages by OrderID without touching protoactor-go's
+// cluster package directly.
+type Cluster struct {
+	c      *cluster.Cluster
+	logger *zap.Logger
+}
+
+// Join starts a cluster member for cfg.Cluster.Name, seeded from
+// cfg.Cluster.SeedNodes, and registers OrderGrain as a cluster kind
+// backed by mongo for replay and recorder for durable event persistence
+// (so grain writes survive a Mongo outage the same way the gRPC services'
+// audit trail does). etcdClient is the connection pkg/discovery already
+// holds open, shared here instead of dialed again.
+func Join(system *actor.ActorSystem, cfg *config.Config, etcdClient *clientv3.Client, mongo *repository.MongoRepository, recorder *audit.Recorder, logger *zap.Logger) (*Cluster, error) {
+	provider, err := etcd.NewWithClient(etcdClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd cluster provider: %w", err)
+	}
+
+	remoteAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port+1)
+	remoteConfig := remote.Configure(cfg.Server.Host, cfg.Server.Port+1)
+
+	orderKind := cluster.NewKind(Kind, actor.PropsFromProducer(NewGrainActor(mongo, recorder, logger.Named("order-grain"))))
+
+	clusterConfig := cluster.Configure(cfg.Cluster.Name, provider, disthash.New(), remoteConfig,
+		cluster.WithKinds(orderKind),
+		cluster.WithSeedNodes(cfg.Cluster.SeedNodes...),
+	)
+
+	c := cluster.New(system, clusterConfig)
+	c.StartMember()
+
+	logger.Info("Order cluster member started",
+		zap.String("cluster", cfg.Cluster.Name),
+		zap.String("remote_addr", remoteAddr))
+
+	return &Cluster{c: c, logger: logger}, nil
+}
+
+// RequestOrderGrain routes msg to the OrderGrain identified by orderID,
+// wherever in the cluster it currently lives, so a caller (the gRPC
+// OrderServer, for stateful/hot orders) doesn't need to know which node
+// owns that order.
+func (oc *Cluster) RequestOrderGrain(ctx context.Context, orderID string, msg interface{}) (interface{}, error) {
+	timeout := defaultCallTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	resp, err := oc.c.Call(orderID, Kind, msg, cluster.WithRequestTimeout(timeout))
+	if err != nil {
+		return nil, fmt.Errorf("order grain call failed for %q: %w", orderID, err)
+	}
+	return resp, nil
+}
+
+// Shutdown leaves the cluster gracefully, deregistering from the
+// provider so other members stop routing OrderGrain traffic here.
+func (oc *Cluster) Shutdown() {
+	oc.c.Shutdown(true)
+}
@@ -0,0 +1,268 @@
+// Package ordercluster runs OrderGrain, a ProtoActor virtual actor keyed
+// by order ID, as a cluster kind shared by the actor demo process and the
+// order gRPC service. Routing through the cluster means a given OrderID
+// is always handled by whichever node currently owns that key, and the
+// grain's state survives a restart by replaying its event log instead of
+// living only in one process's memory.
+package ordercluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asynkron/protoactor-go/actor"
+	"github.com/example/microshop/pkg/audit"
+	"github.com/example/microshop/pkg/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// Kind is the cluster kind name OrderGrain registers under.
+const Kind = "OrderGrain"
+
+// IdleTimeout passivates a grain after this long without a message, so
+// the cluster's in-memory actor map doesn't grow unboundedly with every
+// order ever created. The next message for that OrderID respawns the
+// grain and replays its event log.
+const IdleTimeout = 5 * time.Minute
+
+const (
+	eventOrderCreated       = "OrderCreatedEvent"
+	eventOrderStatusChanged = "OrderStatusChangedEvent"
+)
+
+// OrderItem mirrors models.OrderItem without importing pkg/grpc's proto
+// dependency, so the grain stays usable from both the actor demo and the
+// gRPC service.
+type OrderItem struct {
+	ProductID   string
+	ProductName string
+	Quantity    int32
+	Price       float64
+}
+
+// OrderInfo is the in-memory state an OrderGrain rebuilds from its event
+// log on activation.
+type OrderInfo struct {
+	OrderID   string
+	UserID    string
+	Items     []OrderItem
+	Status    string
+	CreatedAt time.Time
+}
+
+// CreateOrderCluster activates (or, if already active, no-ops against) the
+// grain identified by OrderID and persists an OrderCreatedEvent.
+type CreateOrderCluster struct {
+	OrderID string
+	UserID  string
+	Items   []OrderItem
+}
+
+// UpdateOrderStatusCluster transitions an active grain's status and
+// persists an OrderStatusChangedEvent.
+type UpdateOrderStatusCluster struct {
+	OrderID string
+	Status  string
+}
+
+// GetOrderStatusCluster reads the grain's current status without
+// mutating it.
+type GetOrderStatusCluster struct {
+	OrderID string
+}
+
+// OrderResponse is returned by CreateOrderCluster. Err is set instead of
+// Status/Message being trusted when the grain couldn't durably record
+// the event backing this response.
+type OrderResponse struct {
+	OrderID string
+	Status  string
+	Message string
+	Err     string
+}
+
+// OrderStatus is returned by UpdateOrderStatusCluster and
+// GetOrderStatusCluster. Err is set instead of Status being trusted when
+// the grain couldn't durably record the event backing this response.
+type OrderStatus struct {
+	OrderID string
+	Status  string
+	Err     string
+}
+
+// GrainActor is a virtual actor keyed by OrderID. Its state lives only in
+// memory while the grain is active; durability comes from replaying its
+// event log, persisted as repository.AuditLog rows, on first use after
+// activation.
+type GrainActor struct {
+	mongo    *repository.MongoRepository
+	recorder *audit.Recorder
+	logger   *zap.Logger
+	order    *OrderInfo
+}
+
+// NewGrainActor builds the producer StartMember registers OrderGrain
+// kind with. mongo is used to replay a grain's event log on activation;
+// recorder is used to persist new events durably (Mongo, falling back to
+// the journal on an outage) before a mutation is acknowledged.
+func NewGrainActor(mongo *repository.MongoRepository, recorder *audit.Recorder, logger *zap.Logger) actor.Producer {
+	return func() actor.Actor {
+		return &GrainActor{mongo: mongo, recorder: recorder, logger: logger}
+	}
+}
+
+func (a *GrainActor) Receive(ctx actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case *actor.Started:
+		ctx.SetReceiveTimeout(IdleTimeout)
+
+	case *actor.ReceiveTimeout:
+		ctx.Stop(ctx.Self())
+
+	case *CreateOrderCluster:
+		a.ensureLoaded(msg.OrderID)
+		a.create(ctx, msg)
+
+	case *UpdateOrderStatusCluster:
+		a.ensureLoaded(msg.OrderID)
+		a.updateStatus(ctx, msg)
+
+	case *GetOrderStatusCluster:
+		a.ensureLoaded(msg.OrderID)
+		if a.order != nil {
+			ctx.Respond(&OrderStatus{OrderID: a.order.OrderID, Status: a.order.Status})
+		} else {
+			ctx.Respond(&OrderStatus{OrderID: msg.OrderID, Status: "not found"})
+		}
+	}
+}
+
+// ensureLoaded replays a.order from its event log the first time the
+// grain sees a message for orderID, so passivation and restarts are
+// invisible to callers.
+func (a *GrainActor) ensureLoaded(orderID string) {
+	if a.order != nil || orderID == "" {
+		return
+	}
+	a.order = a.replay(orderID)
+}
+
+// replay rebuilds OrderInfo for orderID from its persisted events,
+// oldest first (GetAuditLogs returns newest first). Returns nil if the
+// order has no events yet.
+func (a *GrainActor) replay(orderID string) *OrderInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logs, err := a.mongo.GetAuditLogs(ctx, orderID, 0)
+	if err != nil {
+		a.logger.Error("failed to replay order grain", zap.String("order_id", orderID), zap.Error(err))
+		return nil
+	}
+
+	var order *OrderInfo
+	for i := len(logs) - 1; i >= 0; i-- {
+		entry := logs[i]
+		switch entry.Action {
+		case eventOrderCreated:
+			order = &OrderInfo{OrderID: orderID, Status: "pending", CreatedAt: entry.CreatedAt}
+			if v, ok := entry.Data["user_id"].(string); ok {
+				order.UserID = v
+			}
+			order.Items = decodeItems(entry.Data["items"])
+		case eventOrderStatusChanged:
+			if order != nil {
+				if v, ok := entry.Data["status"].(string); ok {
+					order.Status = v
+				}
+			}
+		}
+	}
+	return order
+}
+
+// decodeItems round-trips the bson.M's generic "items" value back into
+// []OrderItem. GetAuditLogs hands back already-decoded bson.M, so the
+// fields need a second marshal/unmarshal pass to land on typed structs.
+func decodeItems(raw interface{}) []OrderItem {
+	if raw == nil {
+		return nil
+	}
+	data, err := bson.Marshal(bson.M{"items": raw})
+	if err != nil {
+		return nil
+	}
+	var wrapper struct {
+		Items []OrderItem `bson:"items"`
+	}
+	if err := bson.Unmarshal(data, &wrapper); err != nil {
+		return nil
+	}
+	return wrapper.Items
+}
+
+func (a *GrainActor) create(ctx actor.Context, msg *CreateOrderCluster) {
+	if a.order != nil {
+		ctx.Respond(&OrderResponse{OrderID: a.order.OrderID, Status: a.order.Status, Message: "order already exists"})
+		return
+	}
+
+	order := &OrderInfo{
+		OrderID:   msg.OrderID,
+		UserID:    msg.UserID,
+		Items:     msg.Items,
+		Status:    "pending",
+		CreatedAt: time.Now(),
+	}
+	if err := a.persist(msg.OrderID, eventOrderCreated, bson.M{
+		"user_id": msg.UserID,
+		"items":   msg.Items,
+	}); err != nil {
+		// Don't commit the in-memory mutation: without a durable event,
+		// replay after this grain passivates would never reconstruct the
+		// order, so the client must not be told it was created.
+		ctx.Respond(&OrderResponse{OrderID: msg.OrderID, Err: fmt.Sprintf("failed to persist order: %v", err)})
+		return
+	}
+
+	a.order = order
+	ctx.Respond(&OrderResponse{OrderID: a.order.OrderID, Status: a.order.Status, Message: "order created"})
+}
+
+func (a *GrainActor) updateStatus(ctx actor.Context, msg *UpdateOrderStatusCluster) {
+	if a.order == nil {
+		ctx.Respond(&OrderStatus{OrderID: msg.OrderID, Status: "not found"})
+		return
+	}
+
+	previousStatus := a.order.Status
+	a.order.Status = msg.Status
+	if err := a.persist(msg.OrderID, eventOrderStatusChanged, bson.M{"status": msg.Status}); err != nil {
+		a.order.Status = previousStatus
+		ctx.Respond(&OrderStatus{OrderID: msg.OrderID, Status: previousStatus, Err: fmt.Sprintf("failed to persist status change: %v", err)})
+		return
+	}
+
+	ctx.Respond(&OrderStatus{OrderID: a.order.OrderID, Status: a.order.Status})
+}
+
+// persist durably records an event via the audit recorder (Mongo,
+// falling back to the journal on an outage) before the caller
+// acknowledges the mutation it backs. It returns an error only if the
+// journal fallback itself fails, since a journaled entry still survives
+// a restart — it's just pending replay.
+func (a *GrainActor) persist(orderID, action string, data bson.M) error {
+	if err := a.recorder.RecordSync(&repository.AuditLog{
+		Service:  "order-grain",
+		Action:   action,
+		EntityID: orderID,
+		Data:     data,
+	}); err != nil {
+		a.logger.Error("failed to persist order grain event",
+			zap.String("order_id", orderID), zap.String("action", action), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Package outbox publishes rows appended via repository.Outbox to an
+// EventBus, implementing the relay half of the transactional outbox
+// pattern: poll unpublished rows, publish at-least-once, mark published.
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// EventBus publishes one outbox row's payload to a topic. Swappable so
+// tests (and local dev without a Kafka cluster) can use an in-memory sink
+// instead of Kafka.
+type EventBus interface {
+	Publish(ctx context.Context, topic, key string, payload []byte, headers map[string]string) error
+	Close() error
+}
+
+// KafkaEventBus publishes via a sarama synchronous producer, one message
+// per outbox row, keyed by aggregate ID so Kafka partitions preserve
+// per-aggregate order.
+type KafkaEventBus struct {
+	producer sarama.SyncProducer
+}
+
+// NewKafkaEventBus dials brokers and returns a ready-to-use EventBus.
+func NewKafkaEventBus(brokers []string) (*KafkaEventBus, error) {
+	cfg := sarama.NewConfig()
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Retry.Max = 5
+
+	producer, err := sarama.NewSyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaEventBus{producer: producer}, nil
+}
+
+func (b *KafkaEventBus) Publish(_ context.Context, topic, key string, payload []byte, headers map[string]string) error {
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(payload),
+	}
+	for k, v := range headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+	_, _, err := b.producer.SendMessage(msg)
+	return err
+}
+
+func (b *KafkaEventBus) Close() error {
+	return b.producer.Close()
+}
+
+// Message is one call captured by InMemoryEventBus.
+type Message struct {
+	Topic   string
+	Key     string
+	Payload []byte
+	Headers map[string]string
+}
+
+// InMemoryEventBus collects published messages instead of sending them
+// anywhere, for tests and for running without a Kafka cluster.
+type InMemoryEventBus struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{}
+}
+
+func (b *InMemoryEventBus) Publish(_ context.Context, topic, key string, payload []byte, headers map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, Message{Topic: topic, Key: key, Payload: payload, Headers: headers})
+	return nil
+}
+
+func (b *InMemoryEventBus) Close() error {
+	return nil
+}
+
+// Messages returns a snapshot of everything published so far.
+func (b *InMemoryEventBus) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Message, len(b.messages))
+	copy(out, b.messages)
+	return out
+}
@@ -0,0 +1,173 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/example/microshop/pkg/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBatchSize    = 50
+	defaultPollInterval = time.Second
+	maxBackoff          = 5 * time.Minute
+
+	// claimWindow bounds how long a batch stays invisible to other relay
+	// instances after being claimed, so a crash between the claim and the
+	// publish it was claimed for doesn't strand the row forever — it
+	// just becomes claimable again once the window lapses.
+	claimWindow = 30 * time.Second
+)
+
+// Relay polls outbox_events for unpublished rows and publishes each to
+// bus, on a topic derived from its aggregate type. Publish failures are
+// retried with exponential backoff tracked per row via Attempts.
+type Relay struct {
+	db     *gorm.DB
+	bus    EventBus
+	logger *zap.Logger
+
+	batchSize    int
+	pollInterval time.Duration
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRelay builds a Relay. Call Start to begin polling.
+func NewRelay(db *gorm.DB, bus EventBus, logger *zap.Logger) *Relay {
+	return &Relay{
+		db:           db,
+		bus:          bus,
+		logger:       logger,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background.
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go r.loop()
+}
+
+func (r *Relay) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.drainOnce()
+		}
+	}
+}
+
+// drainOnce claims up to batchSize unpublished rows whose NextAttemptAt
+// has passed, then publishes each to bus. Claiming and publishing are
+// deliberately split into separate transactions: claimBatch's row locks
+// are held only for that one query, not across the Kafka round trips
+// publishOne makes, so a slow or stalled publish can't pin locks other
+// relay instances or writers are waiting on.
+func (r *Relay) drainOnce() {
+	events, err := r.claimBatch()
+	if err != nil {
+		r.logger.Error("outbox: failed to claim batch", zap.Error(err))
+		return
+	}
+
+	for i := range events {
+		r.publishOne(&events[i])
+	}
+}
+
+// claimBatch locks due rows with SKIP LOCKED so multiple relay instances
+// (e.g. one per service replica) never claim the same row, stamps them
+// with a claimWindow-out NextAttemptAt so they stay invisible to other
+// claimers while this instance publishes them, and commits immediately.
+func (r *Relay) claimBatch() ([]repository.OutboxEvent, error) {
+	var events []repository.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", time.Now()).
+			Order("id").
+			Limit(r.batchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(events))
+		claimedUntil := time.Now().Add(claimWindow)
+		for i := range events {
+			ids[i] = events[i].ID
+			events[i].NextAttemptAt = &claimedUntil
+		}
+		return tx.Model(&repository.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", claimedUntil).Error
+	})
+	return events, err
+}
+
+func (r *Relay) publishOne(evt *repository.OutboxEvent) {
+	headers := map[string]string{}
+	if evt.Headers != "" {
+		var raw map[string]int64
+		if err := json.Unmarshal([]byte(evt.Headers), &raw); err == nil {
+			for k, v := range raw {
+				headers[k] = strconv.FormatInt(v, 10)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.bus.Publish(ctx, evt.AggregateType, evt.AggregateID, []byte(evt.Payload), headers); err != nil {
+		evt.Attempts++
+		next := time.Now().Add(backoff(evt.Attempts))
+		evt.NextAttemptAt = &next
+		r.logger.Warn("outbox: publish failed, will retry",
+			zap.Uint64("id", evt.ID), zap.Int("attempts", evt.Attempts), zap.Error(err))
+		if err := r.db.Model(evt).Select("Attempts", "NextAttemptAt").Updates(evt).Error; err != nil {
+			r.logger.Error("outbox: failed to record retry", zap.Error(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	evt.PublishedAt = &now
+	if err := r.db.Model(evt).Select("PublishedAt").Updates(evt).Error; err != nil {
+		r.logger.Error("outbox: failed to mark published", zap.Error(err))
+	}
+}
+
+// backoff grows exponentially with attempts, capped at maxBackoff.
+func backoff(attempts int) time.Duration {
+	d := time.Second << attempts
+	if d <= 0 || d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// Close stops polling for new work and drains the event bus.
+func (r *Relay) Close() error {
+	close(r.done)
+	r.wg.Wait()
+	return r.bus.Close()
+}
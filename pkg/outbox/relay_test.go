@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/microshop/pkg/repository"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newTestRelayDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&repository.OutboxEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// erroringBus always fails to publish, simulating a broker outage so
+// tests can assert the relay's retry path doesn't lose the row.
+type erroringBus struct {
+	err error
+}
+
+func (b *erroringBus) Publish(_ context.Context, _, _ string, _ []byte, _ map[string]string) error {
+	return b.err
+}
+
+func (b *erroringBus) Close() error { return nil }
+
+func TestRelayPublishOneMarksPublishedOnSuccess(t *testing.T) {
+	db := newTestRelayDB(t)
+	bus := NewInMemoryEventBus()
+	relay := NewRelay(db, bus, zap.NewNop())
+
+	evt := &repository.OutboxEvent{
+		AggregateType: "order",
+		AggregateID:   "order-1",
+		EventType:     "OrderCreated",
+		Payload:       `{"order_id":"order-1"}`,
+	}
+	if err := db.Create(evt).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	relay.publishOne(evt)
+
+	if evt.PublishedAt == nil {
+		t.Fatal("expected PublishedAt to be set after a successful publish")
+	}
+	msgs := bus.Messages()
+	if len(msgs) != 1 || msgs[0].Topic != "order" || msgs[0].Key != "order-1" {
+		t.Fatalf("unexpected messages published: %+v", msgs)
+	}
+
+	var stored repository.OutboxEvent
+	if err := db.First(&stored, evt.ID).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if stored.PublishedAt == nil {
+		t.Error("PublishedAt not persisted")
+	}
+}
+
+func TestRelayPublishOneRetriesOnFailureWithoutLosingTheRow(t *testing.T) {
+	db := newTestRelayDB(t)
+	relay := NewRelay(db, &erroringBus{err: errors.New("broker unreachable")}, zap.NewNop())
+
+	evt := &repository.OutboxEvent{
+		AggregateType: "order",
+		AggregateID:   "order-2",
+		EventType:     "OrderCreated",
+		Payload:       `{"order_id":"order-2"}`,
+	}
+	if err := db.Create(evt).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	before := time.Now()
+	relay.publishOne(evt)
+
+	if evt.PublishedAt != nil {
+		t.Error("a failed publish must not mark the row published")
+	}
+	if evt.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", evt.Attempts)
+	}
+	if evt.NextAttemptAt == nil || !evt.NextAttemptAt.After(before) {
+		t.Error("expected NextAttemptAt to be pushed into the future so the row stays claimable later")
+	}
+
+	var stored repository.OutboxEvent
+	if err := db.First(&stored, evt.ID).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if stored.PublishedAt != nil {
+		t.Error("PublishedAt must not be persisted for a failed publish")
+	}
+	if stored.Attempts != 1 {
+		t.Errorf("persisted Attempts = %d, want 1", stored.Attempts)
+	}
+}
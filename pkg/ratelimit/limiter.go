@@ -0,0 +1,59 @@
+// Package ratelimit implements a Redis-backed fixed-window rate limiter
+// shared across gateway replicas.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/microshop/pkg/repository"
+)
+
+// Limiter enforces a per-key request budget within a fixed window using
+// INCR+EXPIRE against Redis, so every gateway replica shares the same
+// counters.
+type Limiter struct {
+	redis *repository.RedisRepository
+}
+
+// NewLimiter wraps the gateway's RedisRepository.
+func NewLimiter(redis *repository.RedisRepository) *Limiter {
+	return &Limiter{redis: redis}
+}
+
+// Result describes the outcome of an Allow check.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Allow increments the counter for key and reports whether it is still
+// within limit requests per window. The first increment in a window sets
+// its expiry, giving a fixed-window approximation of a sliding window
+// that is cheap enough to run on every request.
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	redisKey := fmt.Sprintf("ratelimit:%s", key)
+
+	count, err := l.redis.Incr(ctx, redisKey)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: incr failed: %w", err)
+	}
+	if count == 1 {
+		if err := l.redis.Expire(ctx, redisKey, window); err != nil {
+			return Result{}, fmt.Errorf("ratelimit: expire failed: %w", err)
+		}
+	}
+
+	if int(count) > limit {
+		ttl, err := l.redis.TTL(ctx, redisKey)
+		if err != nil {
+			ttl = window
+		}
+		return Result{Allowed: false, Limit: limit, Remaining: 0, RetryAfter: ttl}, nil
+	}
+
+	return Result{Allowed: true, Limit: limit, Remaining: limit - int(count)}, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is a single row in the outbox_events table. A domain write
+// and the OutboxEvent(s) it produces are always created in the same GORM
+// transaction (see Outbox.Append), so a process crash can never lose an
+// event that the domain write itself committed.
+type OutboxEvent struct {
+	ID            uint64 `gorm:"primaryKey;autoIncrement"`
+	AggregateType string `gorm:"type:varchar(64);not null;index:idx_outbox_aggregate"`
+	AggregateID   string `gorm:"type:varchar(64);not null;index:idx_outbox_aggregate"`
+	EventType     string `gorm:"type:varchar(64);not null"`
+	Sequence      int64  `gorm:"not null"`
+	Payload       string `gorm:"type:json;not null"`
+	Headers       string `gorm:"type:json"`
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+	NextAttemptAt *time.Time
+	Attempts      int
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// Event is what callers hand to Outbox.Append; Payload is marshaled to
+// JSON as-is.
+type Event struct {
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       interface{}
+}
+
+// Outbox turns domain events into outbox_events rows.
+type Outbox struct{}
+
+// NewOutbox returns an Outbox. It is stateless: the per-aggregate
+// sequence is derived from existing rows rather than held in memory, so
+// any number of servers can share one Outbox safely.
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+// Append inserts evt as a row on tx. The caller MUST run this inside the
+// same transaction as the domain write it accompanies:
+//
+//	db.Transaction(func(tx *gorm.DB) error {
+//	    if err := tx.Create(order).Error; err != nil { return err }
+//	    return outbox.Append(tx, repository.Event{...})
+//	})
+//
+// so the two can never be committed independently.
+func (o *Outbox) Append(tx *gorm.DB, evt Event) error {
+	var count int64
+	if err := tx.Model(&OutboxEvent{}).
+		Where("aggregate_type = ? AND aggregate_id = ?", evt.AggregateType, evt.AggregateID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	sequence := count + 1
+
+	payload, err := json.Marshal(evt.Payload)
+	if err != nil {
+		return err
+	}
+	headers, err := json.Marshal(map[string]int64{"sequence": sequence})
+	if err != nil {
+		return err
+	}
+
+	row := &OutboxEvent{
+		AggregateType: evt.AggregateType,
+		AggregateID:   evt.AggregateID,
+		EventType:     evt.EventType,
+		Sequence:      sequence,
+		Payload:       string(payload),
+		Headers:       string(headers),
+		CreatedAt:     time.Now(),
+	}
+	return tx.Create(row).Error
+}
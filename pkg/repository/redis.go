@@ -63,6 +63,18 @@ func (r *RedisRepository) Close() error {
 	return r.client.Close()
 }
 
+func (r *RedisRepository) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+func (r *RedisRepository) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return r.client.Expire(ctx, key, expiration).Err()
+}
+
+func (r *RedisRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.client.TTL(ctx, key).Result()
+}
+
 // Cache for user data
 type UserCache struct {
 	ID    string `json:"id"`
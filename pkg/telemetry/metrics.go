@@ -0,0 +1,71 @@
+// Package telemetry wires Prometheus metrics and OpenTelemetry tracing
+// through the gateway and into the downstream gRPC services.
+package telemetry
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the HTTP-facing Prometheus collectors registered on the
+// gateway's /metrics endpoint.
+type Metrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	inFlight        *prometheus.GaugeVec
+}
+
+// NewMetrics registers the gateway's request counters, latency
+// histograms, and in-flight gauges with the default Prometheus registry,
+// which Handler exposes.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gateway_http_requests_total",
+			Help: "Total HTTP requests handled by the gateway, by route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gateway_http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds, by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gateway_http_requests_in_flight",
+			Help: "Number of HTTP requests currently being handled, by route.",
+		}, []string{"method", "route"}),
+	}
+
+	prometheus.MustRegister(m.requestsTotal, m.requestDuration, m.inFlight)
+	return m
+}
+
+// Middleware records per-route counters, latency, and in-flight gauges.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		m.inFlight.WithLabelValues(c.Request.Method, route).Inc()
+		defer m.inFlight.WithLabelValues(c.Request.Method, route).Dec()
+
+		c.Next()
+
+		m.requestsTotal.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.requestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler exposes the registry in the Prometheus text exposition format.
+func (m *Metrics) Handler() gin.HandlerFunc {
+	h := promhttp.Handler()
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,35 @@
+package telemetry
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a span for every HTTP request, extracting any
+// upstream trace context from inbound headers, and stores it back on
+// c.Request so handlers that read c.Request.Context() propagate it
+// through to the gRPC clients.
+func TracingMiddleware(tracer trace.Tracer) gin.HandlerFunc {
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+c.FullPath())
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		span.SetAttributes(
+			attribute.Int("http.status_code", c.Writer.Status()),
+			attribute.String("http.route", c.FullPath()),
+		)
+		if c.Writer.Status() >= 500 {
+			span.SetStatus(codes.Error, "request failed")
+		}
+	}
+}
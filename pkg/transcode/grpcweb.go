@@ -0,0 +1,71 @@
+package transcode
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// MountGRPCWeb exposes every Rule's FullMethod directly to browser clients
+// under /grpc/<Service>/<Method>, using the grpc-web wire framing (a
+// single 5-byte-prefixed message per request/response; no streaming).
+// Browsers that can't speak raw HTTP/2 gRPC can call these instead of
+// going through gateway.SetupRoutes's hand-written JSON handlers.
+func MountGRPCWeb(router gin.IRoutes, conn *grpc.ClientConn, rules []Rule) {
+	for _, rule := range rules {
+		rule := rule
+		router.POST("/grpc"+rule.FullMethod, func(c *gin.Context) {
+			reqBody, err := readGRPCWebFrame(c.Request.Body)
+			if err != nil {
+				c.Status(http.StatusBadRequest)
+				return
+			}
+
+			req := rule.NewRequest()
+			if err := proto.Unmarshal(reqBody, req); err != nil {
+				c.Status(http.StatusBadRequest)
+				return
+			}
+
+			reply := rule.NewReply()
+			if err := conn.Invoke(c.Request.Context(), rule.FullMethod, req, reply); err != nil {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+
+			replyBody, err := proto.Marshal(reply)
+			if err != nil {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+
+			c.Header("Content-Type", "application/grpc-web+proto")
+			c.Writer.Write(writeGRPCWebFrame(replyBody))
+		})
+	}
+}
+
+// readGRPCWebFrame strips the grpc-web 5-byte frame header (1 flag byte +
+// 4-byte big-endian length) and returns the raw protobuf message.
+func readGRPCWebFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func writeGRPCWebFrame(body []byte) []byte {
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(body)))
+	return append(header, body...)
+}
@@ -0,0 +1,32 @@
+// Package transcode exposes gRPC services to browser clients. MountGRPCWeb
+// (grpcweb.go) mounts one gRPC-Web route per Rule.
+//
+// The package has no JSON/REST transcoder; gateway.SetupRoutes keeps its
+// REST handlers hand-written, because a Rule carries none of what those
+// handlers differ in:
+//
+//   - Every hand-written REST handler in gateway.go picks its own status
+//     codes (200/201/404) and unwraps a different field of its proto
+//     reply (resp.User, resp.Order, or an envelope like
+//     {"users": ..., "total": ...}) rather than writing the reply back
+//     verbatim. None of that follows from FullMethod/NewRequest/NewReply.
+//   - DeleteUser is gated on the admin role at the route level
+//     (auth.RequireRoles("admin")); a Rule has no per-route middleware.
+//
+// Generating those handlers from Rule would take a per-Rule response
+// transform and a per-Rule middleware chain, so each Rule would carry as
+// much handler-specific code as the hand-written function it replaces.
+// gateway.go's hand-written handlers are the source of truth for REST.
+package transcode
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// Rule binds a single unary RPC for MountGRPCWeb. FullMethod is the gRPC
+// wire method, e.g. "/user.UserService/GetUser".
+type Rule struct {
+	FullMethod string
+	NewRequest func() proto.Message
+	NewReply   func() proto.Message
+}